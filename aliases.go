@@ -0,0 +1,117 @@
+package cong
+
+import (
+	"slices"
+	"strings"
+)
+
+// boundEnvVarNames returns the environment variables of a field, the current one first so it wins over the old ones.
+// The last one is the name viper.AutomaticEnv gives the key, e.g. HELLO_SERVERNAME for serverName, which keeps
+// working alongside the snake-cased HELLO_SERVER_NAME.
+func (loader *Loader[T]) boundEnvVarNames(envPrefix string, bound boundField) []string {
+	names := []string{bound.envVar}
+	for _, alias := range bound.aliases {
+		names = append(names, loader.envVarName(envPrefix, alias))
+	}
+
+	automatic := strings.ToUpper(envPrefix + "_" + strings.ReplaceAll(bound.key, ".", "_"))
+	if !slices.Contains(names, automatic) {
+		names = append(names, automatic)
+	}
+
+	return names
+}
+
+// resolveAliases moves the values of old keys in settings to the keys of the fields that replaced them,
+// warning about every old or deprecated key in use. When both the old and the new key are set, the new one wins.
+func (loader *Loader[T]) resolveAliases(state *loadState, settings map[string]any, source string) {
+	for lowerKey, bound := range state.boundFields {
+		if _, ok := lookupSetting(settings, lowerKey); ok && bound.deprecated != "" {
+			loader.options.logger.Warn("deprecated config key",
+				"key", bound.key,
+				"source", source,
+				"message", bound.deprecated,
+			)
+		}
+
+		for _, alias := range bound.aliases {
+			aliasKey := strings.ToLower(alias)
+
+			value, ok := lookupSetting(settings, aliasKey)
+			if !ok {
+				continue
+			}
+
+			loader.options.logger.Warn("deprecated config key",
+				"key", alias,
+				"replacement", bound.key,
+				"source", source,
+			)
+
+			deleteSetting(settings, aliasKey)
+			if _, ok := lookupSetting(settings, lowerKey); !ok {
+				setSetting(settings, lowerKey, value)
+			}
+		}
+	}
+}
+
+// fileLayerSource names the config sources in log messages: the file when there is only one.
+func fileLayerSource(state *loadState) string {
+	if len(state.configFiles) == 1 {
+		return state.configFiles[0].path
+	}
+
+	return "config"
+}
+
+// lookupSetting finds a lower-cased dotted key in nested settings.
+func lookupSetting(settings map[string]any, key string) (any, bool) {
+	name, rest, nested := strings.Cut(key, ".")
+
+	value, ok := settings[name]
+	if !ok || !nested {
+		return value, ok
+	}
+
+	child, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return lookupSetting(child, rest)
+}
+
+func deleteSetting(settings map[string]any, key string) {
+	name, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		delete(settings, name)
+		return
+	}
+
+	child, ok := settings[name].(map[string]any)
+	if !ok {
+		return
+	}
+
+	deleteSetting(child, rest)
+	if len(child) == 0 {
+		delete(settings, name)
+	}
+}
+
+func setSetting(settings map[string]any, key string, value any) {
+	name, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		settings[name] = value
+		return
+	}
+
+	child, ok := settings[name].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		settings[name] = child
+	}
+
+	setSetting(child, rest, value)
+}
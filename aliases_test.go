@@ -0,0 +1,96 @@
+package cong
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type aliasTestServer struct {
+	Port int    `mapstructure:"port" alias:"port,server.listen_port"`
+	Host string `mapstructure:"host" deprecated:"use server.address"`
+}
+
+type aliasTestConfig struct {
+	Name   string          `mapstructure:"name" alias:"title,legacyName"`
+	Server aliasTestServer `mapstructure:"server"`
+}
+
+func Test_Loader_aliases(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[aliasTestConfig](WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	config, err := loader.LoadFromReader("", bytes.NewBufferString("legacyName: hello\nport: 80\n"), YamlExt)
+
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+	as.Equal(80, config.Server.Port)
+	as.Contains(logs.String(), `msg="deprecated config key" key=legacyName replacement=name`)
+	as.Contains(logs.String(), `key=port replacement=server.port`)
+}
+
+func Test_Loader_aliases_newKeyWins(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[aliasTestConfig](WithLogger(slog.New(slog.DiscardHandler)))
+
+	config, err := loader.LoadFromMap("", map[string]any{
+		"name":   "new",
+		"title":  "old",
+		"server": map[string]any{"listen_port": 80, "port": 81},
+	})
+
+	as.Nil(err)
+	as.Equal("new", config.Name)
+	as.Equal(81, config.Server.Port)
+}
+
+func Test_Loader_aliases_env(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("ALIAS_TITLE", "from env")
+	t.Setenv("ALIAS_SERVER_LISTEN_PORT", "8080")
+	t.Setenv("ALIAS_SERVER_HOST", "localhost")
+
+	var logs bytes.Buffer
+	loader := NewLoader[aliasTestConfig](WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	config, err := loader.LoadFromEnv("alias")
+
+	as.Nil(err)
+	as.Equal("from env", config.Name)
+	as.Equal(8080, config.Server.Port)
+	as.Equal("localhost", config.Server.Host)
+	as.Contains(logs.String(), `msg="deprecated environment variable" env=ALIAS_TITLE replacement=ALIAS_NAME`)
+	as.Contains(logs.String(), `msg="deprecated config key" key=server.host env=ALIAS_SERVER_HOST message="use server.address"`)
+}
+
+func Test_Loader_deprecated(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[aliasTestConfig](WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	config, err := loader.LoadFromMap("", map[string]any{"server": map[string]any{"host": "localhost"}})
+
+	as.Nil(err)
+	as.Equal("localhost", config.Server.Host)
+	as.Contains(logs.String(), `key=server.host source=config message="use server.address"`)
+}
+
+func Test_Check_aliases(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/config.yaml"
+	writeTestFile(t, path, "title: hello\nserver:\n  listen_port: 80\n")
+
+	var logs bytes.Buffer
+	loader := NewLoader[aliasTestConfig](WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	as.Nil(loader.Check(path))
+	as.Contains(logs.String(), "key=title replacement=name source="+path)
+}
@@ -0,0 +1,520 @@
+package cong
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UnknownKeyError is reported by Check for a key in a config file that does not map to any field of the config.
+type UnknownKeyError struct {
+	File string
+	Key  string
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("%s: unknown key %q", e.File, e.Key)
+}
+
+type configFile struct {
+	path string
+	ext  ConfigExtension
+}
+
+// Check validates config files without loading the application. See Loader.Check.
+func Check[T any](paths ...string) error {
+	return NewLoader[T]().Check(paths...)
+}
+
+// Check parses the given config files, or the config files inside the given directories (see WithCheckExtension),
+// the same way LoadFromDir does and reports unknown keys, type mismatches, missing required fields and bad enum values.
+// Files are merged in the given order and environment variables are ignored.
+// All problems are returned together; nil means the files are valid.
+func (loader *Loader[T]) Check(paths ...string) error {
+	files, err := collectConfigFiles(paths, loader.options.checkExt)
+	if err != nil {
+		return err
+	}
+
+	config := new(T)
+	state, err := loader.newLoadState("")
+	if err != nil {
+		return err
+	}
+
+	err = loader.bindSnakeCaseParams(state, config, "")
+	if err != nil {
+		return err
+	}
+
+	err = loader.setProgrammaticDefaults(state)
+	if err != nil {
+		return err
+	}
+
+	known := knownKeysOf(reflect.TypeOf(config).Elem())
+	if loader.options.currentVersion != 0 {
+		known.leaves[strings.ToLower(loader.options.versionKey)] = true
+	}
+
+	var errs []error
+	for _, file := range files {
+		documents, err := readConfigFile(file, state.profileSelector())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// documents of other profiles are linted too, but only the selected ones are merged and validated
+		for _, document := range documents {
+			// every file carries its own version, so migrate them before looking for unknown keys
+			if _, err := loader.migrate(document.settings); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file.path, err))
+				continue
+			}
+			loader.resolveAliases(state, document.settings, file.path)
+
+			errs = append(errs, known.unknownKeys(file.path, "", document.settings)...)
+
+			if document.selected {
+				mergeIntoFileLayer(state, document.settings, document.directives)
+			}
+		}
+	}
+
+	if err := loader.mergeFileLayer(state); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	if err := loader.decode(state, config); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckWithSchema validates config files against a schema produced by GenerateJSONSchema. See SchemaChecker.Check.
+func CheckWithSchema(schema *JSONSchema, paths ...string) error {
+	return NewSchemaChecker(schema).Check(paths...)
+}
+
+// SchemaChecker validates config files against a schema produced by GenerateJSONSchema.
+// It is used when the config struct is not available, e.g. by the cong command line tool.
+type SchemaChecker struct {
+	schema  *JSONSchema
+	options options
+}
+
+// NewSchemaChecker creates a checker for the schema. Of the loader options, it uses WithProfile, WithProfileKey
+// and WithCheckExtension.
+func NewSchemaChecker(schema *JSONSchema, opts ...Option) *SchemaChecker {
+	return &SchemaChecker{schema: schema, options: newOptions(opts)}
+}
+
+// Check reports the same kinds of problems as Loader.Check. Keys are matched case-insensitively, like the loader does.
+func (checker *SchemaChecker) Check(paths ...string) error {
+	files, err := collectConfigFiles(paths, checker.options.checkExt)
+	if err != nil {
+		return err
+	}
+
+	selector := profileSelector{
+		key:     strings.ToLower(checker.options.profileKey),
+		profile: checker.options.profile,
+		bound:   schemaProperty(checker.schema, checker.options.profileKey) != nil,
+	}
+	merged := make(map[string]any)
+
+	var errs []error
+	for _, file := range files {
+		documents, err := readConfigFile(file, selector)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, document := range documents {
+			errs = append(errs, schemaUnknownKeys(file.path, checker.schema, "", document.settings)...)
+			resolveSchemaAliases(checker.schema, "", document.settings)
+
+			if document.selected {
+				mergeLayer(nil, merged, document.settings, document.directives)
+			}
+		}
+	}
+
+	var fieldErrs []*FieldError
+	checkSchemaValue(checker.schema, "", merged, &fieldErrs)
+	if len(fieldErrs) > 0 {
+		errs = append(errs, &ValidationError{Errors: fieldErrs})
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectConfigFiles returns the given files and the files with extension dirExt inside the given directories.
+// A nil dirExt is taken from the first file given, or else from the first config file found in a directory.
+func collectConfigFiles(paths []string, dirExt *ConfigExtension) ([]configFile, error) {
+	files := make([]configFile, 0, len(paths))
+
+	if dirExt == nil {
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				if ext, err := configExtensionFromPath(path, YamlExt); err == nil {
+					dirExt = &ext
+					break
+				}
+			}
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &NotFoundError{Paths: []string{path}, Err: err}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			ext, err := configExtensionFromPath(path, YamlExt)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, configFile{path: path, ext: ext})
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			ext, err := ParseConfigExtension(filepath.Ext(path))
+			if err != nil {
+				return nil
+			}
+			if dirExt == nil {
+				dirExt = &ext
+			}
+			if ext == *dirExt {
+				files = append(files, configFile{path: path, ext: ext})
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// readConfigFile parses a config file into its layers of lower-cased settings. See parseConfigSource.
+func readConfigFile(file configFile, selector profileSelector) ([]configDocument, error) {
+	data, err := os.ReadFile(file.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfigSource(file.path, data, file.ext, selector)
+}
+
+// knownKeys holds the lower-cased keys of a config struct: leaves take a single value,
+// open keys (maps and interfaces) accept anything below them and groups are nested structs.
+type knownKeys struct {
+	leaves map[string]bool
+	open   map[string]bool
+	groups map[string]bool
+}
+
+func knownKeysOf(typ reflect.Type) knownKeys {
+	known := knownKeys{
+		leaves: make(map[string]bool),
+		open:   make(map[string]bool),
+		groups: make(map[string]bool),
+	}
+
+	_ = walkFields(reflect.New(typ).Elem(), "", func(key string, field reflect.StructField, _ reflect.Value) error {
+		key = strings.ToLower(key)
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Map || fieldType.Kind() == reflect.Interface {
+			known.open[key] = true
+		} else {
+			known.leaves[key] = true
+		}
+
+		for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+			known.groups[key[:i]] = true
+		}
+
+		return nil
+	})
+
+	return known
+}
+
+func (known knownKeys) unknownKeys(file string, prefix string, settings map[string]any) []error {
+	var errs []error
+
+	for name, value := range settings {
+		key := joinKey(prefix, name)
+
+		switch nested, isMap := value.(map[string]any); {
+		case known.leaves[key] || known.open[key]:
+		case known.groups[key] && isMap:
+			errs = append(errs, known.unknownKeys(file, key, nested)...)
+		default:
+			errs = append(errs, &UnknownKeyError{File: file, Key: key})
+		}
+	}
+
+	return errs
+}
+
+func schemaUnknownKeys(file string, schema *JSONSchema, prefix string, settings map[string]any) []error {
+	var errs []error
+
+	for name, value := range settings {
+		key := joinKey(prefix, name)
+
+		property := schemaProperty(schema, name)
+		if property == nil {
+			property = schema.AdditionalProperties
+		}
+
+		if property == nil || property.Bool != nil {
+			if property != nil && !*property.Bool {
+				errs = append(errs, &UnknownKeyError{File: file, Key: key})
+			}
+			continue
+		}
+
+		if nested, ok := value.(map[string]any); ok && property.Type == "object" {
+			errs = append(errs, schemaUnknownKeys(file, property, key, nested)...)
+		}
+	}
+
+	return errs
+}
+
+// resolveSchemaAliases moves the values of old keys in settings to the keys that replaced them, like the loader does
+// for the alias tag. When both the old and the new key are set, the new one wins.
+func resolveSchemaAliases(schema *JSONSchema, prefix string, settings map[string]any) {
+	for name, property := range schema.Properties {
+		key := joinKey(prefix, strings.ToLower(name))
+
+		if property.ReplacedBy == "" {
+			resolveSchemaAliases(property, key, settings)
+			continue
+		}
+
+		value, ok := lookupSetting(settings, key)
+		if !ok {
+			continue
+		}
+
+		replacement := strings.ToLower(property.ReplacedBy)
+		deleteSetting(settings, key)
+		if _, ok := lookupSetting(settings, replacement); !ok {
+			setSetting(settings, replacement, value)
+		}
+	}
+}
+
+func schemaProperty(schema *JSONSchema, name string) *JSONSchema {
+	for propertyName, property := range schema.Properties {
+		if strings.EqualFold(propertyName, name) {
+			return property
+		}
+	}
+
+	return nil
+}
+
+func checkSchemaValue(schema *JSONSchema, key string, value any, fieldErrs *[]*FieldError) {
+	if schema == nil || schema.Bool != nil {
+		return
+	}
+
+	if !schemaTypeMatches(schema, value) {
+		*fieldErrs = append(*fieldErrs, &FieldError{
+			Key:     key,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be of type %s, got %v", schema.Type, value),
+		})
+		return
+	}
+
+	if len(schema.Enum) > 0 && !schemaEnumContains(schema.Enum, value) {
+		enum := make([]string, 0, len(schema.Enum))
+		for _, enumValue := range schema.Enum {
+			enum = append(enum, fmt.Sprint(enumValue))
+		}
+		*fieldErrs = append(*fieldErrs, &FieldError{
+			Key:     key,
+			Rule:    enumTag,
+			Message: fmt.Sprintf("must be one of [%s], got %v", strings.Join(enum, ", "), value),
+		})
+	}
+
+	switch schema.Type {
+	case "object":
+		settings, _ := value.(map[string]any)
+		checkSchemaObject(schema, key, settings, fieldErrs)
+	case "array":
+		items, _ := value.([]any)
+		for i, item := range items {
+			checkSchemaValue(schema.Items, fmt.Sprintf("%s[%d]", key, i), item, fieldErrs)
+		}
+	}
+}
+
+func checkSchemaObject(schema *JSONSchema, key string, settings map[string]any, fieldErrs *[]*FieldError) {
+	known := make(map[string]bool, len(settings))
+
+	for propertyName, property := range schema.Properties {
+		propertyKey := joinKey(key, propertyName)
+
+		value, found := lookupFold(settings, propertyName)
+		if found {
+			known[strings.ToLower(propertyName)] = true
+			checkSchemaValue(property, propertyKey, value, fieldErrs)
+			continue
+		}
+
+		switch {
+		case schemaRequires(schema, propertyName) && property.Default == nil:
+			*fieldErrs = append(*fieldErrs, &FieldError{Key: propertyKey, Rule: requiredTag, Message: "is required"})
+		case property.Type == "object" && len(property.Properties) > 0:
+			checkSchemaObject(property, propertyKey, nil, fieldErrs)
+		}
+	}
+
+	if schema.AdditionalProperties == nil || schema.AdditionalProperties.Bool != nil {
+		return
+	}
+
+	for name, value := range settings {
+		if !known[strings.ToLower(name)] {
+			checkSchemaValue(schema.AdditionalProperties, joinKey(key, name), value, fieldErrs)
+		}
+	}
+}
+
+func schemaRequires(schema *JSONSchema, name string) bool {
+	for _, required := range schema.Required {
+		if strings.EqualFold(required, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lookupFold(settings map[string]any, name string) (any, bool) {
+	for key, value := range settings {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// schemaTypeMatches reports whether the loader could decode value into the schema type.
+// Like the loader's weak decoding, it accepts scalars written as strings (e.g. port: "80").
+func schemaTypeMatches(schema *JSONSchema, value any) bool {
+	str, isString := value.(string)
+
+	switch schema.Type {
+	case "string":
+		if schema.Pattern != "" && isString {
+			matched, err := regexp.MatchString(schema.Pattern, str)
+			return err == nil && matched
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			return false
+		}
+		return true
+	case "integer":
+		number, ok := toFloat(value)
+		if !ok || number != float64(int64(number)) {
+			return false
+		}
+		return schema.Minimum == nil || number >= *schema.Minimum
+	case "number":
+		number, ok := toFloat(value)
+		return ok && (schema.Minimum == nil || number >= *schema.Minimum)
+	case "boolean":
+		if isString {
+			_, err := strconv.ParseBool(str)
+			return err == nil
+		}
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok || value == nil
+	case "array":
+		_, ok := value.([]any)
+		return ok || isString
+	default:
+		return true
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	case reflect.String:
+		number, err := strconv.ParseFloat(reflected.String(), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func schemaEnumContains(enum []any, value any) bool {
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if !schemaEnumContains(enum, item) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, enumValue := range enum {
+		if fmt.Sprint(enumValue) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
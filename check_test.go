@@ -0,0 +1,127 @@
+package cong
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checkTestApp struct {
+	Name  string `mapstructure:"name" required:"true"`
+	Level string `mapstructure:"level" default:"info" enum:"debug,info,warn"`
+}
+
+type checkTestServer struct {
+	Host    string            `mapstructure:"host" required:"true"`
+	Port    int               `mapstructure:"port"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+type checkTestConfig struct {
+	App    checkTestApp    `mapstructure:"app"`
+	Server checkTestServer `mapstructure:"server"`
+}
+
+func Test_Check(t *testing.T) {
+	as := assert.New(t)
+
+	err := Check[checkTestConfig]("./testdata/check/valid")
+
+	as.Nil(err)
+}
+
+func Test_Check_invalid(t *testing.T) {
+	as := assert.New(t)
+
+	err := Check[checkTestConfig]("./testdata/check/invalid/config.yaml")
+
+	var unknownKeyErr *UnknownKeyError
+	as.True(errors.As(err, &unknownKeyErr))
+	as.Equal("app.colour", unknownKeyErr.Key)
+
+	var decodeErr *DecodeError
+	as.True(errors.As(err, &decodeErr))
+	as.Equal("server.port", decodeErr.Key)
+}
+
+func Test_Check_dirExtension(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "app.yaml"), "app:\n  name: hello\nserver:\n  host: localhost\n")
+	writeTestFile(t, filepath.Join(dir, "schema.json"), `{"type": "object"}`)
+
+	as.Nil(Check[checkTestConfig](dir))
+
+	err := NewLoader[checkTestConfig](WithCheckExtension(JsonExt)).Check(dir)
+
+	var unknownKeyErr *UnknownKeyError
+	as.True(errors.As(err, &unknownKeyErr))
+	as.Equal("type", unknownKeyErr.Key)
+}
+
+func Test_Check_validation(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		App checkTestApp `mapstructure:"app"`
+	}
+
+	err := Check[TestConfig]("./testdata/check/invalid/config.yaml")
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Len(validationErr.Errors, 1)
+	as.Equal("app.level", validationErr.Errors[0].Key)
+	as.Equal(enumTag, validationErr.Errors[0].Rule)
+}
+
+func Test_CheckWithSchema(t *testing.T) {
+	as := assert.New(t)
+
+	schema, err := GenerateJSONSchema[checkTestConfig]()
+	as.Nil(err)
+
+	as.Nil(CheckWithSchema(schema, "./testdata/check/valid"))
+
+	err = CheckWithSchema(schema, "./testdata/check/invalid")
+
+	var unknownKeyErr *UnknownKeyError
+	as.True(errors.As(err, &unknownKeyErr))
+	as.Equal("app.colour", unknownKeyErr.Key)
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+
+	rules := make(map[string]string)
+	for _, fieldErr := range validationErr.Errors {
+		rules[fieldErr.Key] = fieldErr.Rule
+	}
+	as.Equal(map[string]string{
+		"app.level":   enumTag,
+		"server.host": requiredTag,
+		"server.port": "type",
+	}, rules)
+}
+
+func Test_Loader_Load_validationError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+		Host       string `required:"true"`
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.Load("hello", YamlExt, "./testdata/loadYaml")
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Equal("Host", validationErr.Errors[0].Key)
+	as.Equal("HELLO_HOST", validationErr.Errors[0].EnvVar)
+}
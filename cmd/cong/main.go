@@ -0,0 +1,112 @@
+// Command cong validates config files against a JSON Schema generated with cong.GenerateJSONSchema,
+// so config changes can be linted in CI without booting the application.
+//
+// Usage:
+//
+//	cong check -schema config.schema.json [-profile prod] [-ext yaml] config/ overrides.yaml
+//
+// It prints every problem found and exits with status 1 when the files are invalid.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kolobok-kelbek/cong"
+)
+
+const usage = "usage: cong check -schema <schema.json> [-profile <name>] [-ext <extension>] <file or dir>..."
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "check" {
+		_, _ = fmt.Fprintln(stderr, usage)
+		return 2
+	}
+
+	flagSet := flag.NewFlagSet("check", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	schemaPath := flagSet.String("schema", "", "path to the JSON Schema generated by cong.GenerateJSONSchema")
+	profile := flagSet.String("profile", "", "profile selecting the documents of multi-document YAML files")
+	ext := flagSet.String("ext", "", "extension of the config files read from directories, "+
+		"by default that of the first file given or found")
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *schemaPath == "" || flagSet.NArg() == 0 {
+		_, _ = fmt.Fprintln(stderr, usage)
+		return 2
+	}
+
+	schema, err := readSchema(*schemaPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	opts := []cong.Option{cong.WithProfile(*profile)}
+	if *ext != "" {
+		configExt, err := cong.ParseConfigExtension(*ext)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 2
+		}
+		opts = append(opts, cong.WithCheckExtension(configExt))
+	}
+
+	err = cong.NewSchemaChecker(schema, opts...).Check(flagSet.Args()...)
+	if err == nil {
+		_, _ = fmt.Fprintln(stdout, "ok")
+		return 0
+	}
+
+	for _, problem := range problems(err) {
+		_, _ = fmt.Fprintln(stderr, problem)
+	}
+
+	return 1
+}
+
+func readSchema(path string) (*cong.JSONSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := new(cong.JSONSchema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+
+	return schema, nil
+}
+
+// problems flattens joined errors and validation errors into one line per problem.
+func problems(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var res []error
+		for _, e := range joined.Unwrap() {
+			res = append(res, problems(e)...)
+		}
+		return res
+	}
+
+	var validationErr *cong.ValidationError
+	if errors.As(err, &validationErr) {
+		res := make([]error, 0, len(validationErr.Errors))
+		for _, fieldErr := range validationErr.Errors {
+			res = append(res, fieldErr)
+		}
+		return res
+	}
+
+	return []error{err}
+}
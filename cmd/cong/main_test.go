@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolobok-kelbek/cong"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	App struct {
+		Name  string `mapstructure:"name" required:"true"`
+		Level string `mapstructure:"level" enum:"debug,info,warn"`
+	} `mapstructure:"app"`
+}
+
+func writeSchema(t *testing.T) string {
+	t.Helper()
+
+	schema, err := cong.GenerateJSONSchema[testConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func Test_run(t *testing.T) {
+	as := assert.New(t)
+
+	schemaPath := writeSchema(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "-schema", schemaPath, "../../testdata/check/valid/app.yaml"}, &stdout, &stderr)
+
+	as.Equal(0, code)
+	as.Equal("ok\n", stdout.String())
+}
+
+func Test_run_invalid(t *testing.T) {
+	as := assert.New(t)
+
+	schemaPath := writeSchema(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "-schema", schemaPath, "../../testdata/check/invalid/config.yaml"}, &stdout, &stderr)
+
+	as.Equal(1, code)
+	as.Contains(stderr.String(), `unknown key "app.colour"`)
+	as.Contains(stderr.String(), `unknown key "server"`)
+	as.Contains(stderr.String(), `key "app.level" must be one of [debug, info, warn], got verbose`)
+}
+
+func Test_run_usage(t *testing.T) {
+	as := assert.New(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check"}, &stdout, &stderr)
+
+	as.Equal(2, code)
+	as.Contains(stderr.String(), "usage")
+}
+
+func Test_run_profile(t *testing.T) {
+	as := assert.New(t)
+
+	schemaPath := writeSchema(t)
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "app:\n  name: hello\n---\nprofile: prod\napp:\n  level: verbose\n---\nprofile: dev\napp:\n  colour: red\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "-schema", schemaPath, "-profile", "prod", path}, &stdout, &stderr)
+
+	as.Equal(1, code)
+	as.Contains(stderr.String(), `key "app.level" must be one of [debug, info, warn], got verbose`)
+	as.Contains(stderr.String(), `unknown key "app.colour"`)
+
+	stderr.Reset()
+	code = run([]string{"check", "-schema", schemaPath, path}, &stdout, &stderr)
+
+	as.Equal(1, code)
+	as.NotContains(stderr.String(), "app.level")
+	as.Contains(stderr.String(), `unknown key "app.colour"`)
+}
+
+func Test_run_ext(t *testing.T) {
+	as := assert.New(t)
+
+	schemaPath := writeSchema(t)
+	dir := filepath.Dir(schemaPath)
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("app:\n  name: hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "-schema", schemaPath, "-ext", "yaml", dir}, &stdout, &stderr)
+
+	as.Equal(0, code)
+	as.Equal("ok\n", stdout.String())
+
+	code = run([]string{"check", "-schema", schemaPath, "-ext", "json", dir}, &stdout, &stderr)
+
+	as.Equal(1, code)
+	as.Contains(stderr.String(), `unknown key "type"`)
+
+	stderr.Reset()
+	code = run([]string{"check", "-schema", schemaPath, "-ext", "docx", dir}, &stdout, &stderr)
+
+	as.Equal(2, code)
+	as.Contains(stderr.String(), "unsupported config extension")
+}
@@ -1,6 +1,12 @@
 package cong
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 type ConfigExtension int
 
@@ -23,3 +29,24 @@ const (
 func (configExtension ConfigExtension) String() string {
 	return viper.SupportedExts[configExtension]
 }
+
+// ParseConfigExtension returns the ConfigExtension for a file extension such as "yaml" or ".yaml".
+func ParseConfigExtension(ext string) (ConfigExtension, error) {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	for i, supported := range viper.SupportedExts {
+		if supported == ext {
+			return ConfigExtension(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported config extension %q", ext)
+}
+
+func configExtensionFromPath(path string, fallback ConfigExtension) (ConfigExtension, error) {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return fallback, nil
+	}
+
+	return ParseConfigExtension(ext)
+}
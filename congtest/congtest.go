@@ -0,0 +1,153 @@
+// Package congtest helps unit testing configs loaded with cong without temp files or os.Setenv:
+//
+//	cfg := congtest.Load[Config](t, congtest.YAML("port: 80"), congtest.Env{"PORT": "8080"})
+//
+// Config sources live in an in-memory file system and the environment is injected, so tests are hermetic
+// and can run in parallel. Golden helpers snapshot the effective config and the env var documentation.
+package congtest
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kolobok-kelbek/cong"
+)
+
+// DefaultProject is the project name, and so the env var prefix, used unless Project is given.
+const DefaultProject = "app"
+
+const configDir = "config"
+
+// Source is a part of the test setup passed to Load: config content, environment variables, the project name or loader options.
+type Source interface {
+	apply(s *setup)
+}
+
+type setup struct {
+	project string
+	ext     cong.ConfigExtension
+	files   []string
+	env     Env
+	options []cong.Option
+	err     error
+}
+
+type file struct {
+	ext     cong.ConfigExtension
+	content string
+}
+
+func (f file) apply(s *setup) {
+	if len(s.files) > 0 && s.ext != f.ext {
+		s.err = fmt.Errorf("config sources must share one format, got %s and %s", s.ext, f.ext)
+		return
+	}
+
+	s.ext = f.ext
+	s.files = append(s.files, f.content)
+}
+
+// YAML adds a YAML config layer. Layers are merged in the order they are passed, later ones win.
+func YAML(content string) Source {
+	return file{ext: cong.YamlExt, content: content}
+}
+
+// JSON adds a JSON config layer. See YAML.
+func JSON(content string) Source {
+	return file{ext: cong.JsonExt, content: content}
+}
+
+// TOML adds a TOML config layer. See YAML.
+func TOML(content string) Source {
+	return file{ext: cong.TomlExt, content: content}
+}
+
+// Env sets environment variables. Names are given without the project prefix: with the default project,
+// Env{"SERVER_PORT": "8080"} sets APP_SERVER_PORT. The process environment is never read.
+type Env map[string]string
+
+func (env Env) apply(s *setup) {
+	for name, value := range env {
+		s.env[name] = value
+	}
+}
+
+// Project sets the project name, which is the env var prefix. DefaultProject is used otherwise.
+type Project string
+
+func (project Project) apply(s *setup) {
+	s.project = string(project)
+}
+
+type options []cong.Option
+
+func (opts options) apply(s *setup) {
+	s.options = append(s.options, opts...)
+}
+
+// Options passes options to the loader, e.g. cong.WithMigrations. They are applied after the test environment,
+// so cong.WithLogger replaces the default logger that writes to the test log.
+func Options(opts ...cong.Option) Source {
+	return options(opts)
+}
+
+// Load loads T from the sources and fails the test when loading fails.
+func Load[T any](t testing.TB, sources ...Source) *T {
+	t.Helper()
+
+	config, err := LoadErr[T](t, sources...)
+	if err != nil {
+		t.Fatalf("congtest: failed to load config: %v", err)
+	}
+
+	return config
+}
+
+// LoadErr loads T from the sources and returns the loader error, for testing invalid configs.
+// Without config content only the defaults and the environment are used, like cong.Loader.LoadFromEnv.
+func LoadErr[T any](t testing.TB, sources ...Source) (*T, error) {
+	t.Helper()
+
+	s := &setup{project: DefaultProject, env: make(Env)}
+	for _, source := range sources {
+		source.apply(s)
+	}
+	if s.err != nil {
+		t.Fatalf("congtest: %v", s.err)
+	}
+
+	env := make(map[string]string, len(s.env))
+	for name, value := range s.env {
+		env[strings.ToUpper(s.project)+"_"+name] = value
+	}
+
+	opts := append([]cong.Option{
+		cong.WithEnvMap(env),
+		cong.WithLogger(slog.New(slog.NewTextHandler(testWriter{t}, nil))),
+	}, s.options...)
+	loader := cong.NewLoader[T](opts...)
+
+	if len(s.files) == 0 {
+		return loader.LoadFromEnv(s.project)
+	}
+
+	fsys := make(fstest.MapFS, len(s.files))
+	for i, content := range s.files {
+		fsys[fmt.Sprintf("%s/%03d.%s", configDir, i, s.ext)] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	return loader.LoadFromFS(s.project, fsys, configDir, s.ext)
+}
+
+// testWriter sends loader log lines to the test log, so they are only shown for failed or verbose tests.
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,94 @@
+package congtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kolobok-kelbek/cong"
+	"github.com/stretchr/testify/assert"
+)
+
+type testServer struct {
+	Host string `mapstructure:"host" default:"localhost" desc:"Listen host"`
+	Port int    `mapstructure:"port" required:"true" desc:"Listen port"`
+}
+
+type testConfig struct {
+	Name     string     `mapstructure:"name" default:"hello"`
+	Password string     `mapstructure:"password"`
+	Server   testServer `mapstructure:"server"`
+}
+
+func Test_Load(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	config := Load[testConfig](t,
+		YAML("server:\n  port: 80\n"),
+		YAML("name: layered\n"),
+		Env{"SERVER_PORT": "8080"},
+	)
+
+	as.Equal(&testConfig{Name: "layered", Server: testServer{Host: "localhost", Port: 8080}}, config)
+}
+
+func Test_Load_project(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	config := Load[testConfig](t, Project("hello"), JSON(`{"server": {"port": 80}}`), Env{"NAME": "from env"})
+
+	as.Equal("from env", config.Name)
+	as.Equal(80, config.Server.Port)
+}
+
+func Test_Load_envOnly(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	config := Load[testConfig](t, Env{"SERVER_PORT": "8080"})
+
+	as.Equal(8080, config.Server.Port)
+}
+
+func Test_LoadErr(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	_, err := LoadErr[testConfig](t, TOML("name = \"hello\"\n"))
+
+	var validationErr *cong.ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Equal("server.port", validationErr.Errors[0].Key)
+	as.Equal("APP_SERVER_PORT", validationErr.Errors[0].EnvVar)
+}
+
+func Test_Load_options(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	config := Load[testConfig](t,
+		YAML("title: migrated\nserver:\n  port: 80\n"),
+		Options(cong.WithMigrations(2, cong.Migration{From: 1, Migrate: func(settings map[string]any) error {
+			settings["name"] = settings["title"]
+			delete(settings, "title")
+			return nil
+		}})),
+	)
+
+	as.Equal("migrated", config.Name)
+}
+
+func Test_GoldenConfig(t *testing.T) {
+	t.Parallel()
+
+	config := Load[testConfig](t, YAML("password: hunter2\nserver:\n  port: 80\n"))
+
+	GoldenConfig(t, "config", config)
+}
+
+func Test_GoldenEnvVars(t *testing.T) {
+	t.Parallel()
+
+	GoldenEnvVars[testConfig](t, "env_vars", DefaultProject)
+}
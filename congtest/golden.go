@@ -0,0 +1,77 @@
+package congtest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolobok-kelbek/cong"
+	"go.yaml.in/yaml/v3"
+)
+
+var update = flag.Bool("congtest.update", false, "rewrite the congtest golden files instead of comparing them")
+
+// Golden compares data with the golden file testdata/<name>.golden. Run the tests with -congtest.update
+// to create or rewrite the golden files.
+func Golden(t testing.TB, name string, data []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("congtest: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("congtest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("congtest: golden file %s does not exist, run the tests with -congtest.update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("congtest: %v", err)
+	}
+
+	if !bytes.Equal(want, data) {
+		t.Errorf("congtest: %s does not match, run the tests with -congtest.update if the change is expected\n--- want\n%s\n--- got\n%s",
+			path, want, data)
+	}
+}
+
+// GoldenConfig snapshots the effective config as YAML, with secret values redacted (see cong.Redact).
+func GoldenConfig[T any](t testing.TB, name string, config *T) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cong.Redact(config)); err != nil {
+		t.Fatalf("congtest: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("congtest: %v", err)
+	}
+
+	Golden(t, name, buf.Bytes())
+}
+
+// GoldenEnvVars snapshots the Markdown documentation of the environment variables of T (see cong.EnvVars),
+// so a renamed or removed variable shows up in review.
+func GoldenEnvVars[T any](t testing.TB, name string, projectName string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := cong.RenderEnvVars(&buf, cong.EnvVars[T](projectName), cong.EnvVarsMarkdown); err != nil {
+		t.Fatalf("congtest: %v", err)
+	}
+
+	Golden(t, name, buf.Bytes())
+}
@@ -0,0 +1,242 @@
+package cong
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// decoderOptions configures the decoding of the merged settings into the config struct.
+// The hooks are viper's defaults with Optional support added; strict mode replaces the weak coercion of scalars.
+func (loader *Loader[T]) decoderOptions() []viper.DecoderConfigOption {
+	if !loader.options.strictTypes {
+		return []viper.DecoderConfigOption{
+			viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+				optionalDecodeHook,
+				mapstructure.StringToTimeDurationHookFunc(),
+				stringToWeakSliceHook(","),
+			)),
+		}
+	}
+
+	return []viper.DecoderConfigOption{
+		viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+			optionalDecodeHook,
+			strictScalarHook,
+			stringToWeakSliceHook(","),
+		)),
+		func(config *mapstructure.DecoderConfig) {
+			config.WeaklyTypedInput = false
+		},
+	}
+}
+
+// stringToWeakSliceHook splits strings, e.g. from env vars, into slices of any element type, like viper does.
+func stringToWeakSliceHook(sep string) mapstructure.DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || to.Kind() != reflect.Slice {
+			return data, nil
+		}
+
+		raw := data.(string)
+		if raw == "" {
+			return []string{}, nil
+		}
+
+		return strings.Split(raw, sep), nil
+	}
+}
+
+// strictScalarHook converts values into bools, numbers, strings and durations without weak coercion:
+// strings (e.g. from env vars) are parsed in full, numbers must fit the target type exactly
+// and values of any other kind are rejected.
+func strictScalarHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from == to {
+		return data, nil
+	}
+
+	if to == durationType {
+		return strictDuration(data)
+	}
+
+	val := reflect.ValueOf(data)
+	out := reflect.New(to).Elem()
+
+	switch to.Kind() {
+	case reflect.Bool:
+		switch from.Kind() {
+		case reflect.Bool:
+			out.SetBool(val.Bool())
+		case reflect.String:
+			b, err := strconv.ParseBool(val.String())
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid bool, use true or false", val.String())
+			}
+			out.SetBool(b)
+		default:
+			return nil, unexpectedTypeError(data, to)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strictInt(val, to)
+		if err != nil {
+			return nil, err
+		}
+		out.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strictUint(val, to)
+		if err != nil {
+			return nil, err
+		}
+		out.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strictFloat(val, to)
+		if err != nil {
+			return nil, err
+		}
+		out.SetFloat(f)
+	case reflect.String:
+		if from.Kind() != reflect.String {
+			return nil, unexpectedTypeError(data, to)
+		}
+		out.SetString(val.String())
+	default:
+		return data, nil
+	}
+
+	return out.Interface(), nil
+}
+
+func strictInt(val reflect.Value, to reflect.Type) (int64, error) {
+	var i int64
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows %s", val.Uint(), to)
+		}
+		i = int64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("%v overflows %s", f, to)
+		}
+		i = int64(f)
+	case reflect.String:
+		parsed, err := strconv.ParseInt(val.String(), 10, 64)
+		if err != nil {
+			return 0, parseNumberError(val.String(), to, err)
+		}
+		i = parsed
+	default:
+		return 0, unexpectedTypeError(val.Interface(), to)
+	}
+
+	if reflect.Zero(to).OverflowInt(i) {
+		return 0, fmt.Errorf("%d overflows %s", i, to)
+	}
+
+	return i, nil
+}
+
+func strictUint(val reflect.Value, to reflect.Type) (uint64, error) {
+	var u uint64
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val.Int() < 0 {
+			return 0, fmt.Errorf("%d overflows %s", val.Int(), to)
+		}
+		u = uint64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = val.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("%v overflows %s", f, to)
+		}
+		u = uint64(f)
+	case reflect.String:
+		parsed, err := strconv.ParseUint(val.String(), 10, 64)
+		if err != nil {
+			return 0, parseNumberError(val.String(), to, err)
+		}
+		u = parsed
+	default:
+		return 0, unexpectedTypeError(val.Interface(), to)
+	}
+
+	if reflect.Zero(to).OverflowUint(u) {
+		return 0, fmt.Errorf("%d overflows %s", u, to)
+	}
+
+	return u, nil
+}
+
+func strictFloat(val reflect.Value, to reflect.Type) (float64, error) {
+	var f float64
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = val.Float()
+	case reflect.String:
+		parsed, err := strconv.ParseFloat(val.String(), 64)
+		if err != nil {
+			return 0, parseNumberError(val.String(), to, err)
+		}
+		f = parsed
+	default:
+		return 0, unexpectedTypeError(val.Interface(), to)
+	}
+
+	if reflect.Zero(to).OverflowFloat(f) {
+		return 0, fmt.Errorf("%v overflows %s", f, to)
+	}
+
+	return f, nil
+}
+
+// strictDuration accepts duration strings only: a bare number has no unit and would silently mean nanoseconds.
+func strictDuration(data any) (time.Duration, error) {
+	raw, ok := data.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected a duration string such as \"30s\", got %T %v", data, data)
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid duration", raw)
+	}
+
+	return duration, nil
+}
+
+func parseNumberError(raw string, to reflect.Type, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s overflows %s", raw, to)
+	}
+
+	return fmt.Errorf("%q is not a valid %s", raw, to)
+}
+
+func unexpectedTypeError(data any, to reflect.Type) error {
+	return fmt.Errorf("expected %s, got %T %v", to, data, data)
+}
@@ -0,0 +1,97 @@
+package cong
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type strictTestConfig struct {
+	Port    int            `mapstructure:"port"`
+	Small   int8           `mapstructure:"small"`
+	Count   uint           `mapstructure:"count"`
+	Ratio   float32        `mapstructure:"ratio"`
+	Debug   bool           `mapstructure:"debug"`
+	Name    string         `mapstructure:"name"`
+	Timeout time.Duration  `mapstructure:"timeout"`
+	Ports   []int          `mapstructure:"ports"`
+	Retries Optional[int8] `mapstructure:"retries"`
+}
+
+func Test_Loader_WithStrictTypes(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings map[string]any
+		message  string
+	}{
+		{name: "string for int", settings: map[string]any{"port": "abc"}, message: `"abc" is not a valid int`},
+		{name: "empty string for int", settings: map[string]any{"port": ""}, message: `"" is not a valid int`},
+		{name: "overflow", settings: map[string]any{"small": 300}, message: "300 overflows int8"},
+		{name: "string overflow", settings: map[string]any{"small": "300"}, message: "300 overflows int8"},
+		{name: "negative uint", settings: map[string]any{"count": -1}, message: "-1 overflows uint"},
+		{name: "float for int", settings: map[string]any{"port": 1.5}, message: "1.5 is not an integer"},
+		{name: "float overflow", settings: map[string]any{"ratio": 1e39}, message: "1e+39 overflows float32"},
+		{name: "bad bool", settings: map[string]any{"debug": "yes"}, message: `"yes" is not a valid bool, use true or false`},
+		{name: "int for bool", settings: map[string]any{"debug": 1}, message: "expected bool, got int 1"},
+		{name: "int for string", settings: map[string]any{"name": 123}, message: "expected string, got int 123"},
+		{name: "bad duration", settings: map[string]any{"timeout": "30s "}, message: `"30s " is not a valid duration`},
+		{name: "number for duration", settings: map[string]any{"timeout": 30}, message: `expected a duration string such as "30s", got int 30`},
+		{name: "list item", settings: map[string]any{"ports": []any{80, "x"}}, message: `"x" is not a valid int`},
+		{name: "optional", settings: map[string]any{"retries": 128}, message: "128 overflows int8"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			as := assert.New(t)
+
+			_, err := NewLoader[strictTestConfig](WithStrictTypes()).LoadFromMap("", c.settings)
+
+			var decodeErr *DecodeError
+			as.True(errors.As(err, &decodeErr))
+			as.EqualError(decodeErr.Err, c.message)
+			as.Equal(Source{Kind: SourceConfig, Name: mapSourceName}, decodeErr.Source)
+		})
+	}
+}
+
+func Test_Loader_WithStrictTypes_valid(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "hello.yaml"), "port: 8080\nsmall: -128\nratio: 1\ntimeout: 1m\nports: [80, 443]\n")
+
+	loader := NewLoader[strictTestConfig](WithStrictTypes(), WithEnvMap(map[string]string{
+		"HELLO_COUNT":   "7",
+		"HELLO_DEBUG":   "true",
+		"HELLO_NAME":    "hello",
+		"HELLO_RETRIES": "3",
+	}))
+
+	config, err := loader.Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(strictTestConfig{
+		Port:    8080,
+		Small:   -128,
+		Count:   7,
+		Ratio:   1,
+		Debug:   true,
+		Name:    "hello",
+		Timeout: time.Minute,
+		Ports:   []int{80, 443},
+		Retries: Optional[int8]{Value: 3, Set: true, Source: Source{Kind: SourceEnv, Name: "HELLO_RETRIES"}},
+	}, *config)
+}
+
+func Test_Loader_WithStrictTypes_envSource(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[strictTestConfig](WithStrictTypes(), WithEnvMap(map[string]string{"HELLO_PORT": "80 "}))
+
+	_, err := loader.LoadFromEnv("hello")
+
+	as.EqualError(err, `failed to decode key "port" (env HELLO_PORT) into int: "80 " is not a valid int`)
+}
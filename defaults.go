@@ -0,0 +1,67 @@
+package cong
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Defaulter is implemented by configs, or nested config structs, that compute their defaults in code,
+// e.g. from the CPU count or the hostname. SetDefaults is called on a fresh config (a copy of the WithBase value,
+// if any) before every load, nested structs first.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// WithBase makes the loader start from a copy of base instead of the zero config. Its non-zero fields are defaults:
+// the lowest layer under config sources and env, taking precedence over default tags.
+// base must be of the loader's config type.
+func WithBase[T any](base *T) Option {
+	return func(o *options) {
+		o.base = base
+	}
+}
+
+// setProgrammaticDefaults adds the non-zero fields of the WithBase value and of the SetDefaults methods
+// as defaults, replacing the default tags of the same keys.
+func (loader *Loader[T]) setProgrammaticDefaults(state *loadState) error {
+	defaults := new(T)
+	if loader.options.base != nil {
+		base, ok := loader.options.base.(*T)
+		if !ok {
+			return fmt.Errorf("base value of type %T does not match the config type %T", loader.options.base, defaults)
+		}
+		*defaults = *base
+	}
+
+	callDefaulters(reflect.ValueOf(defaults).Elem())
+
+	return walkFields(reflect.ValueOf(defaults).Elem(), "", func(key string, _ reflect.StructField, value reflect.Value) error {
+		if value.IsZero() {
+			return nil
+		}
+
+		state.viper.SetDefault(key, plainValue(value))
+		state.sources[strings.ToLower(key)] = Source{Kind: SourceDefault}
+
+		return nil
+	})
+}
+
+// callDefaulters calls the SetDefaults methods of the nested structs of a struct, then of the struct itself.
+func callDefaulters(val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if _, ok := fieldName(typ.Field(i)); !ok {
+			continue
+		}
+
+		if isNestedStruct(typ.Field(i).Type) {
+			callDefaulters(val.Field(i))
+		}
+	}
+
+	if defaulter, ok := val.Addr().Interface().(Defaulter); ok {
+		defaulter.SetDefaults()
+	}
+}
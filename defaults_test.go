@@ -0,0 +1,89 @@
+package cong
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultsTestServer struct {
+	Host    string        `mapstructure:"host" default:"localhost"`
+	Port    int           `mapstructure:"port" default:"80"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (s *defaultsTestServer) SetDefaults() {
+	s.Port = 8080
+	s.Timeout = 5 * time.Second
+}
+
+type defaultsTestConfig struct {
+	Name    string             `mapstructure:"name"`
+	Workers int                `mapstructure:"workers" required:"true"`
+	Tags    []string           `mapstructure:"tags"`
+	Server  defaultsTestServer `mapstructure:"server"`
+}
+
+func (c *defaultsTestConfig) SetDefaults() {
+	c.Workers = 4
+	if c.Server.Port == 8080 {
+		c.Server.Host = "0.0.0.0"
+	}
+}
+
+func Test_Loader_SetDefaults(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.yaml")
+	writeTestFile(t, path, "server:\n  timeout: 1s\n")
+
+	loader := NewLoader[defaultsTestConfig](WithEnvMap(map[string]string{"HELLO_WORKERS": "8"}))
+
+	config, err := loader.Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(8, config.Workers)
+	as.Equal("0.0.0.0", config.Server.Host)
+	as.Equal(8080, config.Server.Port)
+	as.Equal(time.Second, config.Server.Timeout)
+
+	source, _ := loader.Metadata().Source("server.port")
+	as.Equal(Source{Kind: SourceDefault}, source)
+	source, _ = loader.Metadata().Source("server.timeout")
+	as.Equal(Source{Kind: SourceConfig, Name: path}, source)
+	as.False(loader.IsSet("name"))
+
+	config, err = loader.LoadFromMap("hello", map[string]any{})
+
+	as.Nil(err)
+	as.Equal(8, config.Workers)
+	as.Equal(5*time.Second, config.Server.Timeout)
+}
+
+func Test_Loader_WithBase(t *testing.T) {
+	as := assert.New(t)
+
+	base := &defaultsTestConfig{Name: "base", Tags: []string{"a"}, Workers: 2}
+	loader := NewLoader[defaultsTestConfig](WithBase(base), WithEnvMap(map[string]string{}))
+
+	config, err := loader.LoadFromMap("hello", map[string]any{"tags": []string{"b", "c"}})
+
+	as.Nil(err)
+	as.Equal("base", config.Name)
+	as.Equal([]string{"b", "c"}, config.Tags)
+	as.Equal(4, config.Workers)
+	as.Equal(8080, config.Server.Port)
+	as.Equal(&defaultsTestConfig{Name: "base", Tags: []string{"a"}, Workers: 2}, base)
+
+	config, err = loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal([]string{"a"}, config.Tags)
+
+	_, err = NewLoader[defaultsTestConfig](WithBase(&defaultsTestServer{})).LoadFromEnv("hello")
+
+	as.EqualError(err, "base value of type *cong.defaultsTestServer does not match the config type *cong.defaultsTestConfig")
+}
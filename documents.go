@@ -0,0 +1,127 @@
+package cong
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// defaultProfileKey selects the documents of a multi-document YAML file that apply to the profile set with WithProfile.
+const defaultProfileKey = "profile"
+
+// profileSelector picks the documents of multi-document YAML files by the value of their profile key.
+type profileSelector struct {
+	key     string
+	profile string
+	// bound is set when the key is also a config field, which the selector would swallow
+	bound bool
+}
+
+// configDocument is a single layer of a config source: the whole source, or one document of a multi-document
+// YAML file. Documents of another profile are not selected: they are checked, but not merged.
+type configDocument struct {
+	data       []byte
+	settings   map[string]any
+	directives mergeDirectives
+	selected   bool
+	// profile is the value of the profile key, removed from settings
+	profile any
+}
+
+// parseConfigSource parses a config source into its layers, in merge order. The documents of a multi-document
+// YAML file are successive layers; those with a profile key are only selected when it names the active profile.
+func parseConfigSource(name string, data []byte, ext ConfigExtension, selector profileSelector) ([]configDocument, error) {
+	documents := [][]byte{data}
+	if ext == YamlExt || ext == YmlExt {
+		split, err := splitYAMLDocuments(data)
+		if err != nil {
+			return nil, newParseError(name, data, err)
+		}
+		if len(split) > 1 {
+			documents = split
+		}
+	}
+
+	if len(documents) > 1 && selector.bound {
+		return nil, fmt.Errorf("%s: the profile key %q of multi-document YAML files is a config field, "+
+			"choose another one with WithProfileKey", name, selector.key)
+	}
+
+	layers := make([]configDocument, 0, len(documents))
+	for _, document := range documents {
+		v := viper.New()
+		v.SetConfigType(ext.String())
+		if err := v.ReadConfig(bytes.NewReader(document)); err != nil {
+			return nil, newParseError(name, data, err)
+		}
+
+		settings := v.AllSettings()
+		selected := true
+		var documentProfile any
+		if len(documents) > 1 {
+			var ok bool
+			documentProfile, ok = settings[selector.key]
+			selected = !ok || matchesProfile(documentProfile, selector.profile)
+			delete(settings, selector.key)
+		}
+
+		layers = append(layers, configDocument{
+			data:       document,
+			settings:   settings,
+			directives: sourceMergeDirectives(document, ext),
+			selected:   selected,
+			profile:    documentProfile,
+		})
+	}
+
+	return layers, nil
+}
+
+// splitYAMLDocuments returns the non-empty documents of a "---" separated YAML stream.
+func splitYAMLDocuments(data []byte) ([][]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var documents [][]byte
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(node.Content) == 0 {
+			continue
+		}
+
+		document, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+}
+
+// matchesProfile reports whether the profile key of a document, a name or a list of names, includes the profile.
+func matchesProfile(documentProfile any, profile string) bool {
+	if profile == "" {
+		return false
+	}
+
+	if names, ok := anySlice(documentProfile); ok {
+		for _, name := range names {
+			if strings.EqualFold(fmt.Sprint(name), profile) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return strings.EqualFold(fmt.Sprint(documentProfile), profile)
+}
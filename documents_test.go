@@ -0,0 +1,115 @@
+package cong
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type documentsTestConfig struct {
+	Name    string   `mapstructure:"name"`
+	Port    int      `mapstructure:"port" default:"80"`
+	Debug   bool     `mapstructure:"debug"`
+	Plugins []string `mapstructure:"plugins" merge:"append"`
+}
+
+const documentsTestYAML = `
+name: base
+plugins: [auth]
+---
+profile: dev
+debug: true
+---
+profile: [staging, prod]
+port: 443
+plugins: [metrics]
+---
+name: override
+`
+
+func Test_Loader_multiDocumentYAML(t *testing.T) {
+	as := assert.New(t)
+
+	config, err := NewLoader[documentsTestConfig]().LoadFromReader("", bytes.NewBufferString(documentsTestYAML), YamlExt)
+
+	as.Nil(err)
+	as.Equal(&documentsTestConfig{Name: "override", Port: 80, Plugins: []string{"auth"}}, config)
+
+	config, err = NewLoader[documentsTestConfig](WithProfile("prod")).LoadFromReader("", bytes.NewBufferString(documentsTestYAML), YamlExt)
+
+	as.Nil(err)
+	as.Equal(&documentsTestConfig{Name: "override", Port: 443, Plugins: []string{"auth", "metrics"}}, config)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.yaml")
+	writeTestFile(t, path, documentsTestYAML)
+
+	loader := NewLoader[documentsTestConfig](WithProfile("dev"))
+
+	config, err = loader.Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(&documentsTestConfig{Name: "override", Port: 80, Debug: true, Plugins: []string{"auth"}}, config)
+
+	source, _ := loader.Metadata().Source("debug")
+	as.Equal(Source{Kind: SourceConfig, Name: path}, source)
+
+	as.Nil(loader.Check(path))
+}
+
+func Test_Loader_multiDocumentYAML_parseError(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[documentsTestConfig]().LoadFromReader("", bytes.NewBufferString("name: a\n---\nport: [\n"), YamlExt)
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+	as.Equal(readerSourceName, parseErr.File)
+	as.Equal(3, parseErr.Line)
+}
+
+func Test_Loader_multiDocumentYAML_profileKey(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Profile string `mapstructure:"profile"`
+		Port    int    `mapstructure:"port"`
+	}
+
+	content := "profile: dev\nport: 1\n---\nport: 2\n"
+
+	_, err := NewLoader[TestConfig]().LoadFromReader("", bytes.NewBufferString(content), YamlExt)
+
+	as.EqualError(err, `<reader>: the profile key "profile" of multi-document YAML files is a config field, `+
+		`choose another one with WithProfileKey`)
+
+	config, err := NewLoader[TestConfig](WithProfileKey("env")).LoadFromReader("", bytes.NewBufferString(content), YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{Profile: "dev", Port: 2}, config)
+
+	config, err = NewLoader[TestConfig]().LoadFromReader("", bytes.NewBufferString("profile: dev\nport: 1\n"), YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{Profile: "dev", Port: 1}, config)
+}
+
+func Test_Loader_Check_multiDocumentYAML(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, "name: base\n---\nprofile: prod\nport: x\n---\nprofile: dev\ncolour: red\n")
+
+	err := NewLoader[documentsTestConfig](WithProfile("prod")).Check(path)
+
+	var decodeErr *DecodeError
+	as.True(errors.As(err, &decodeErr))
+	as.Equal("port", decodeErr.Key)
+
+	var unknownKeyErr *UnknownKeyError
+	as.True(errors.As(err, &unknownKeyErr))
+	as.Equal("colour", unknownKeyErr.Key)
+}
@@ -0,0 +1,230 @@
+package cong
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/subosito/gotenv"
+)
+
+// envLookup returns the value of an environment variable and whether it is set, like os.LookupEnv.
+type envLookup func(name string) (string, bool)
+
+// environment returns the environment set with WithEnviron, WithEnvMap or WithEnvLookup, or the process environment.
+func (loader *Loader[T]) environment() envLookup {
+	if loader.options.lookupEnv != nil {
+		return loader.options.lookupEnv
+	}
+
+	return os.LookupEnv
+}
+
+// newEnvLookup returns the environment the loader binds against: the loader's environment,
+// falling back to the dotenv files when WithDotenv is used.
+func (loader *Loader[T]) newEnvLookup() (envLookup, error) {
+	lookupEnv := loader.environment()
+
+	if !loader.options.dotenv {
+		return lookupEnv, nil
+	}
+
+	values, err := loader.readDotenvFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(name string) (string, bool) {
+		if value, ok := lookupEnv(name); ok {
+			return value, true
+		}
+
+		value, ok := values[name]
+		return value, ok
+	}, nil
+}
+
+// dotenvFiles returns the dotenv files read by WithDotenv, ordered from the lowest to the highest precedence.
+func (loader *Loader[T]) dotenvFiles() []string {
+	names := []string{".env", ".env.local"}
+	if loader.options.dotenvProfile != "" {
+		names = append(names, ".env."+loader.options.dotenvProfile)
+	}
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, filepath.Join(loader.options.dotenvDir, name))
+	}
+
+	return files
+}
+
+// readDotenvFiles merges the variables of the existing dotenv files. Missing files are skipped.
+func (loader *Loader[T]) readDotenvFiles() (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, file := range loader.dotenvFiles() {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			loader.options.logger.Debug("dotenv file not found", "file", file)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		env, err := parseDotenv(data, loader.environment())
+		if err != nil {
+			return nil, newParseError(file, data, err)
+		}
+		loader.options.logger.Debug("dotenv file read", "file", file, "variables", len(env))
+
+		for name, value := range env {
+			values[name] = value
+		}
+	}
+
+	return values, nil
+}
+
+// Markers standing in for "$" and an escaped "\$" while gotenv parses a dotenv file, so that it cannot expand
+// references from the process environment.
+const (
+	dotenvDollar        = "\uE000"
+	dotenvEscapedDollar = "\uE001"
+)
+
+var dotenvReference = regexp.MustCompile(dotenvDollar + `\{?([A-Z0-9_]+)\}?`)
+
+// parseDotenv parses a dotenv file like gotenv.StrictParse, but expands ${VAR} and $VAR references through
+// lookupEnv, so an injected environment is used instead of the process one. Like gotenv, references fall back to
+// the variables defined earlier in the file and single-quoted values are not expanded.
+func parseDotenv(data []byte, lookupEnv envLookup) (map[string]string, error) {
+	text := strings.ReplaceAll(string(data), `\$`, dotenvEscapedDollar)
+	text = strings.ReplaceAll(text, "$", dotenvDollar)
+
+	env, err := gotenv.StrictParse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(env))
+	for _, entry := range dotenvEntries(text) {
+		value, ok := env[entry.key]
+		if !ok {
+			continue
+		}
+
+		if entry.literal {
+			value = strings.ReplaceAll(value, dotenvEscapedDollar, `\$`)
+		} else {
+			value = dotenvReference.ReplaceAllStringFunc(value, func(reference string) string {
+				name := dotenvReference.FindStringSubmatch(reference)[1]
+				if replacement, ok := lookupEnv(name); ok {
+					return replacement
+				}
+				return values[name]
+			})
+			value = strings.ReplaceAll(value, dotenvEscapedDollar, "$")
+		}
+
+		values[entry.key] = strings.ReplaceAll(value, dotenvDollar, "$")
+	}
+
+	return values, nil
+}
+
+type dotenvEntry struct {
+	key string
+	// literal is set for single-quoted values
+	literal bool
+}
+
+// dotenvEntries lists the variables of a dotenv file in order, following the line and quote rules of gotenv.
+func dotenvEntries(text string) []dotenvEntry {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	var entries []dotenvEntry
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			idx = strings.Index(line, ":")
+		}
+		if idx <= 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(strings.TrimPrefix(line[:idx], "export "))
+		value := strings.TrimSpace(line[idx+1:])
+
+		literal := strings.HasPrefix(value, "'")
+
+		// a quoted value continues up to the line holding its closing quote
+		if literal || strings.HasPrefix(value, `"`) {
+			quote := value[:1]
+			closed := strings.Contains(value[1:], quote)
+			for !closed && i+1 < len(lines) {
+				i++
+				closed = strings.Contains(lines[i], quote)
+			}
+		}
+
+		entries = append(entries, dotenvEntry{key: key, literal: literal})
+	}
+
+	return entries
+}
+
+// applyEnv puts the values of the environment variables on top of every other source.
+// Besides the bound variables, keys only known from config sources, e.g. entries of map fields,
+// can be overridden with <PREFIX>_<KEY> like viper.AutomaticEnv does. Empty variables are ignored.
+func (loader *Loader[T]) applyEnv(state *loadState) {
+	if state.lookupEnv == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(state.boundFields))
+	for key := range state.boundFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		bound := state.boundFields[key]
+
+		for _, envVar := range bound.envVars {
+			value, ok := state.lookupEnv(envVar)
+			if !ok || value == "" {
+				continue
+			}
+
+			loader.logEnvOverride(bound, envVar, value)
+			state.viper.Set(bound.key, value)
+			state.sources[key] = Source{Kind: SourceEnv, Name: envVar}
+			break
+		}
+	}
+
+	for _, key := range state.viper.AllKeys() {
+		if _, ok := state.boundFields[key]; ok {
+			continue
+		}
+
+		envVar := strings.ToUpper(state.envPrefix + "_" + strings.ReplaceAll(key, ".", "_"))
+		if value, ok := state.lookupEnv(envVar); ok && value != "" {
+			loader.options.logger.Debug("env override", "key", key, "env", envVar)
+			state.viper.Set(key, value)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package cong
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subosito/gotenv"
+)
+
+type envTestServer struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port"`
+}
+
+type envTestConfig struct {
+	Name   string        `mapstructure:"name"`
+	Level  string        `mapstructure:"level"`
+	Server envTestServer `mapstructure:"server"`
+}
+
+func Test_Loader_dotenv(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".env"), "HELLO_NAME=base\nHELLO_LEVEL=info\nHELLO_SERVER_PORT=80\n")
+	writeTestFile(t, filepath.Join(dir, ".env.local"), "HELLO_LEVEL=debug\n")
+	writeTestFile(t, filepath.Join(dir, ".env.staging"), "HELLO_SERVER_PORT=8080\n")
+
+	t.Setenv("HELLO_NAME", "from env")
+
+	loader := NewLoader[envTestConfig](WithDotenv(dir, "staging"))
+
+	config, err := loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal("from env", config.Name)
+	as.Equal("debug", config.Level)
+	as.Equal(8080, config.Server.Port)
+	as.Equal("localhost", config.Server.Host)
+
+	_, set := os.LookupEnv("HELLO_LEVEL")
+	as.False(set)
+}
+
+func Test_Loader_dotenv_overridesFiles(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".env"), "HELLO_SERVER_PORT=8080\n")
+	writeTestFile(t, filepath.Join(dir, "hello.yaml"), "name: file\nserver:\n  port: 80\n")
+
+	loader := NewLoader[envTestConfig](WithDotenv(dir, ""))
+
+	config, err := loader.Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal("file", config.Name)
+	as.Equal(8080, config.Server.Port)
+}
+
+func Test_Loader_dotenv_expansion(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".env"), `HELLO_NAME=${PROBE_HOST_VAR}
+HELLO_LEVEL='$PROBE_HOST_VAR'
+HELLO_SERVER_HOST="$HELLO_NAME-\$PROBE_HOST_VAR"
+`)
+
+	t.Setenv("PROBE_HOST_VAR", "from-process")
+
+	loader := NewLoader[envTestConfig](WithDotenv(dir, ""), WithEnvMap(map[string]string{"PROBE_HOST_VAR": "injected"}))
+
+	config, err := loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal("injected", config.Name)
+	as.Equal("$PROBE_HOST_VAR", config.Level)
+	as.Equal("injected-$PROBE_HOST_VAR", config.Server.Host)
+}
+
+func Test_parseDotenv_matchesGotenv(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("PROBE_HOST_VAR", "host")
+
+	data := `# comment
+export A=$PROBE_HOST_VAR
+B="${A}:${PROBE_HOST_VAR} \$A"
+C='${A} \$A'
+D=plain\$A $ $UNSET_PROBE_VAR
+E: "multi
+line $A"
+F=${}
+`
+
+	expected, err := gotenv.StrictParse(strings.NewReader(data))
+	as.Nil(err)
+
+	actual, err := parseDotenv([]byte(data), os.LookupEnv)
+	as.Nil(err)
+	as.Equal(map[string]string(expected), actual)
+}
+
+func Test_Loader_dotenv_missingFiles(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[envTestConfig](WithDotenv(t.TempDir(), "prod"))
+
+	config, err := loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal("localhost", config.Server.Host)
+}
+
+func Test_Loader_dotenv_malformed(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".env.local"), "HELLO_NAME=ok\nnot a variable\n")
+
+	loader := NewLoader[envTestConfig](WithDotenv(dir, ""))
+
+	_, err := loader.LoadFromEnv("hello")
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+	as.Equal(filepath.Join(dir, ".env.local"), parseErr.File)
+}
+
+func Test_Loader_env_mapEntries(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Headers map[string]string `mapstructure:"headers"`
+	}
+
+	t.Setenv("HELLO_HEADERS_ACCEPT", "text/plain")
+
+	config, err := NewLoader[TestConfig]().LoadFromMap("hello", map[string]any{
+		"headers": map[string]any{"accept": "application/json", "host": "example.com"},
+	})
+
+	as.Nil(err)
+	as.Equal(map[string]string{"accept": "text/plain", "host": "example.com"}, config.Headers)
+}
+
+func Test_Loader_env_automaticEnvNames(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string `mapstructure:"serverName"`
+		LogLevel   string `mapstructure:"logLevel"`
+	}
+
+	loader := NewLoader[TestConfig](WithEnvMap(map[string]string{
+		"HELLO_SERVERNAME": "automatic",
+		"HELLO_LOG_LEVEL":  "snake",
+		"HELLO_LOGLEVEL":   "automatic",
+	}))
+
+	config, err := loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal("automatic", config.ServerName)
+	as.Equal("snake", config.LogLevel)
+}
+
+func Test_Loader_injectedEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option Option
+	}{
+		{name: "environ", option: WithEnviron([]string{"INJECTED_NAME=hello", "INJECTED_SERVER_PORT=8080"})},
+		{name: "map", option: WithEnvMap(map[string]string{"INJECTED_NAME": "hello", "INJECTED_SERVER_PORT": "8080"})},
+		{name: "lookup", option: WithEnvLookup(func(name string) (string, bool) {
+			value, ok := map[string]string{"INJECTED_NAME": "hello", "INJECTED_SERVER_PORT": "8080"}[name]
+			return value, ok
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			as := assert.New(t)
+
+			config, err := NewLoader[envTestConfig](tt.option).LoadFromEnv("injected")
+
+			as.Nil(err)
+			as.Equal("hello", config.Name)
+			as.Equal(8080, config.Server.Port)
+		})
+	}
+}
+
+func Test_Loader_injectedEnv_ignoresProcessEnv(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("HELLO_NAME", "from process")
+	t.Setenv("HELLO_CONFIG", "./testdata/loadYaml/hello.yaml")
+
+	loader := NewLoader[envTestConfig](
+		WithEnvMap(map[string]string{"HELLO_LEVEL": "debug"}),
+		WithOptionalFile(),
+		WithConfigEnv(),
+	)
+
+	config, err := loader.Load("hello", YamlExt, t.TempDir())
+
+	as.Nil(err)
+	as.Equal("", config.Name)
+	as.Equal("debug", config.Level)
+}
+
+func Test_Loader_injectedEnv_configFile(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "custom.yaml"), "name: custom\n")
+
+	loader := NewLoader[envTestConfig](
+		WithEnvMap(map[string]string{"HELLO_CONFIG": filepath.Join(dir, "custom.yaml")}),
+		WithConfigEnv(),
+	)
+
+	config, err := loader.Load("hello", YamlExt)
+
+	as.Nil(err)
+	as.Equal("custom", config.Name)
+}
+
+func Test_Loader_injectedEnv_standardSearchPaths(t *testing.T) {
+	t.Parallel()
+	as := assert.New(t)
+
+	home := t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".config", "injected", "injected.yaml"), "name: from home\n")
+
+	loader := NewLoader[envTestConfig](
+		WithStandardSearchPaths(),
+		WithEnvMap(map[string]string{"HOME": home, "USERPROFILE": home, "home": home}),
+	)
+
+	config, err := loader.Load("injected", YamlExt)
+
+	as.Nil(err)
+	as.Equal("from home", config.Name)
+}
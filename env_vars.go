@@ -0,0 +1,152 @@
+package cong
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// EnvVar describes an environment variable the loader binds to a config key.
+type EnvVar struct {
+	Name        string
+	Key         string
+	Type        reflect.Type
+	Default     string
+	HasDefault  bool
+	Required    bool
+	Description string
+}
+
+// EnvVarsFormat selects how RenderEnvVars renders the list of environment variables.
+type EnvVarsFormat int
+
+const (
+	// EnvVarsMarkdown renders a Markdown table.
+	EnvVarsMarkdown EnvVarsFormat = iota
+	// EnvVarsDotenv renders a commented .env.example template.
+	EnvVarsDotenv
+	// EnvVarsKubernetes renders a Kubernetes container env: snippet.
+	EnvVarsKubernetes
+)
+
+// EnvVars lists every environment variable bound for T with the given project prefix. See Loader.EnvVars.
+func EnvVars[T any](projectName string) []EnvVar {
+	return NewLoader[T]().EnvVars(projectName)
+}
+
+// EnvVars lists every environment variable the loader binds for the project prefix, in struct field order,
+// e.g. HELLO_SERVER_NAME for the key ServerName.
+func (loader *Loader[T]) EnvVars(projectName string) []EnvVar {
+	var envVars []EnvVar
+
+	_ = walkFields(reflect.ValueOf(new(T)).Elem(), "", func(key string, field reflect.StructField, _ reflect.Value) error {
+		typ := field.Type
+		if inner, ok := optionalInner(typ); ok {
+			typ = inner
+		}
+
+		defaultValue, hasDefault := field.Tag.Lookup(defaultTag)
+		envVars = append(envVars, EnvVar{
+			Name:        loader.envVarName(projectName, key),
+			Key:         key,
+			Type:        typ,
+			Default:     defaultValue,
+			HasDefault:  hasDefault,
+			Required:    isRequired(field),
+			Description: field.Tag.Get(descTag),
+		})
+
+		return nil
+	})
+
+	return envVars
+}
+
+// RenderEnvVars writes the environment variables to w in the given format.
+func RenderEnvVars(w io.Writer, envVars []EnvVar, format EnvVarsFormat) error {
+	var sb strings.Builder
+
+	switch format {
+	case EnvVarsMarkdown:
+		renderEnvVarsMarkdown(&sb, envVars)
+	case EnvVarsDotenv:
+		renderEnvVarsDotenv(&sb, envVars)
+	case EnvVarsKubernetes:
+		renderEnvVarsKubernetes(&sb, envVars)
+	default:
+		return fmt.Errorf("unsupported env vars format %d", format)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func renderEnvVarsMarkdown(sb *strings.Builder, envVars []EnvVar) {
+	sb.WriteString("| Variable | Key | Type | Default | Required | Description |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+
+	for _, envVar := range envVars {
+		var defaultValue string
+		if envVar.HasDefault {
+			defaultValue = "`" + envVar.Default + "`"
+		}
+
+		var required string
+		if envVar.Required {
+			required = "yes"
+		}
+
+		_, _ = fmt.Fprintf(sb, "| `%s` | `%s` | `%s` | %s | %s | %s |\n",
+			envVar.Name,
+			envVar.Key,
+			envVar.Type,
+			escapeMarkdownCell(defaultValue),
+			required,
+			escapeMarkdownCell(envVar.Description),
+		)
+	}
+}
+
+func renderEnvVarsDotenv(sb *strings.Builder, envVars []EnvVar) {
+	for i, envVar := range envVars {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if envVar.Description != "" {
+			_, _ = fmt.Fprintf(sb, "# %s\n", envVar.Description)
+		}
+
+		_, _ = fmt.Fprintf(sb, "# key: %s, type: %s", envVar.Key, envVar.Type)
+		if envVar.Required {
+			sb.WriteString(", required")
+		}
+		sb.WriteString("\n")
+
+		// optional variables without a default stay commented out, so the template does not override anything
+		if !envVar.Required && !envVar.HasDefault {
+			sb.WriteString("# ")
+		}
+		_, _ = fmt.Fprintf(sb, "%s=%s\n", envVar.Name, envVar.Default)
+	}
+}
+
+func renderEnvVarsKubernetes(sb *strings.Builder, envVars []EnvVar) {
+	sb.WriteString("env:\n")
+
+	for _, envVar := range envVars {
+		if envVar.Description != "" {
+			_, _ = fmt.Fprintf(sb, "  # %s\n", envVar.Description)
+		}
+
+		// JSON strings are valid YAML double-quoted scalars
+		value, _ := json.Marshal(envVar.Default)
+		_, _ = fmt.Fprintf(sb, "  - name: %s\n    value: %s\n", envVar.Name, value)
+	}
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,75 @@
+package cong
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envVarsTestServer struct {
+	Name string `desc:"Server name"`
+	Port int    `default:"80" required:"true"`
+}
+
+type envVarsTestConfig struct {
+	Server  envVarsTestServer
+	Timeout int `mapstructure:"timeout"`
+}
+
+func Test_EnvVars(t *testing.T) {
+	as := assert.New(t)
+
+	envVars := EnvVars[envVarsTestConfig]("hello")
+
+	as.Len(envVars, 3)
+	as.Equal("HELLO_SERVER_NAME", envVars[0].Name)
+	as.Equal("Server.Name", envVars[0].Key)
+	as.Equal("Server name", envVars[0].Description)
+	as.Equal("HELLO_SERVER_PORT", envVars[1].Name)
+	as.Equal("80", envVars[1].Default)
+	as.True(envVars[1].HasDefault)
+	as.True(envVars[1].Required)
+	as.Equal("HELLO_TIMEOUT", envVars[2].Name)
+	as.Equal("int", envVars[2].Type.String())
+}
+
+func Test_RenderEnvVars(t *testing.T) {
+	as := assert.New(t)
+
+	envVars := EnvVars[envVarsTestConfig]("hello")
+
+	var markdown strings.Builder
+	as.Nil(RenderEnvVars(&markdown, envVars, EnvVarsMarkdown))
+	as.Equal(`| Variable | Key | Type | Default | Required | Description |
+|---|---|---|---|---|---|
+| `+"`HELLO_SERVER_NAME` | `Server.Name` | `string` |  |  | Server name |"+`
+| `+"`HELLO_SERVER_PORT` | `Server.Port` | `int` | `80` | yes |  |"+`
+| `+"`HELLO_TIMEOUT` | `timeout` | `int` |  |  |  |"+`
+`, markdown.String())
+
+	var dotenv strings.Builder
+	as.Nil(RenderEnvVars(&dotenv, envVars, EnvVarsDotenv))
+	as.Equal(`# Server name
+# key: Server.Name, type: string
+# HELLO_SERVER_NAME=
+
+# key: Server.Port, type: int, required
+HELLO_SERVER_PORT=80
+
+# key: timeout, type: int
+# HELLO_TIMEOUT=
+`, dotenv.String())
+
+	var kubernetes strings.Builder
+	as.Nil(RenderEnvVars(&kubernetes, envVars, EnvVarsKubernetes))
+	as.Equal(`env:
+  # Server name
+  - name: HELLO_SERVER_NAME
+    value: ""
+  - name: HELLO_SERVER_PORT
+    value: "80"
+  - name: HELLO_TIMEOUT
+    value: ""
+`, kubernetes.String())
+}
@@ -0,0 +1,186 @@
+package cong
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+)
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// NotFoundError is returned when the loader could not find a config file or directory.
+// Paths holds every location that was searched.
+type NotFoundError struct {
+	Name  string
+	Ext   ConfigExtension
+	Paths []string
+	Err   error
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("config path not found: %s", strings.Join(e.Paths, ", "))
+	}
+
+	return fmt.Sprintf("config file %q not found in: %s", e.Name+"."+e.Ext.String(), strings.Join(e.Paths, ", "))
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is returned when a config file exists but its content is malformed.
+// Line and Column are 1-based and set to 0 when the underlying parser does not report them.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+
+	return fmt.Sprintf("failed to parse config %s: %v", location, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when a value cannot be decoded into the config struct.
+// Key is the dotted config key, EnvVar is the environment variable bound to it, Source is where the value
+// came from and Type is the target Go type.
+type DecodeError struct {
+	Key    string
+	EnvVar string
+	Source Source
+	Type   reflect.Type
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	var target string
+	if e.Type != nil {
+		target = " into " + e.Type.String()
+	}
+
+	var source string
+	switch {
+	case e.Source.Kind != "":
+		source = " (" + e.Source.String() + ")"
+	case e.EnvVar != "":
+		source = " (env " + e.EnvVar + ")"
+	}
+
+	return fmt.Sprintf("failed to decode key %q%s%s: %v", e.Key, source, target, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(file string, data []byte, err error) *ParseError {
+	var viperErr viper.ConfigParseError
+	if errors.As(err, &viperErr) {
+		err = viperErr.Unwrap()
+	}
+
+	parseErr := &ParseError{File: file, Err: err}
+
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var tomlErr *toml.DecodeError
+
+	switch {
+	case errors.As(err, &jsonSyntaxErr):
+		parseErr.Line, parseErr.Column = offsetToPosition(data, jsonSyntaxErr.Offset)
+	case errors.As(err, &jsonTypeErr):
+		parseErr.Line, parseErr.Column = offsetToPosition(data, jsonTypeErr.Offset)
+	case errors.As(err, &tomlErr):
+		parseErr.Line, parseErr.Column = tomlErr.Position()
+	default:
+		if match := yamlLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+			parseErr.Line, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	return parseErr
+}
+
+func offsetToPosition(data []byte, offset int64) (int, int) {
+	if offset <= 0 || offset > int64(len(data)) {
+		return 0, 0
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+
+	return line, column
+}
+
+func (loader *Loader[T]) newDecodeError(state *loadState, err error) error {
+	var fieldErrs []error
+	collectDecodeErrors(err, &fieldErrs, state)
+	if len(fieldErrs) == 0 {
+		return err
+	}
+
+	return errors.Join(fieldErrs...)
+}
+
+func collectDecodeErrors(err error, fieldErrs *[]error, state *loadState) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			collectDecodeErrors(e, fieldErrs, state)
+		}
+		return
+	}
+
+	var msErr *mapstructure.DecodeError
+	if !errors.As(err, &msErr) {
+		return
+	}
+
+	// nested struct errors are reported by mapstructure as a single error wrapping the joined field errors
+	var nested []error
+	collectDecodeErrors(msErr.Unwrap(), &nested, state)
+	if len(nested) > 0 {
+		*fieldErrs = append(*fieldErrs, nested...)
+		return
+	}
+
+	// errors inside an Optional are reported on its Value field
+	key := msErr.Name()
+	if trimmed, ok := strings.CutSuffix(key, ".Value"); ok {
+		if _, bound := state.boundFields[strings.ToLower(trimmed)]; bound {
+			key = trimmed
+		}
+	}
+	bound := state.boundFields[strings.ToLower(key)]
+	// items of lists and maps ("ports[1]") come from the source of the field
+	fieldKey, _, _ := strings.Cut(key, "[")
+	*fieldErrs = append(*fieldErrs, &DecodeError{
+		Key:    key,
+		EnvVar: bound.envVar,
+		Source: state.sources[strings.ToLower(fieldKey)],
+		Type:   bound.typ,
+		Err:    msErr.Unwrap(),
+	})
+}
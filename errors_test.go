@@ -0,0 +1,98 @@
+package cong
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Loader_Load_notFoundError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.Load("missing", YamlExt, "./testdata/loadYaml", "./testdata/loadDirYaml")
+
+	var notFoundErr *NotFoundError
+	as.True(errors.As(err, &notFoundErr))
+	as.Equal("missing", notFoundErr.Name)
+	as.Equal(YamlExt, notFoundErr.Ext)
+	as.Equal([]string{"./testdata/loadYaml", "./testdata/loadDirYaml"}, notFoundErr.Paths)
+}
+
+func Test_Loader_LoadFromDir_notFoundError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.LoadFromDir("hello", "./testdata/missing", YamlExt)
+
+	var notFoundErr *NotFoundError
+	as.True(errors.As(err, &notFoundErr))
+	as.Len(notFoundErr.Paths, 1)
+}
+
+func Test_Loader_Load_parseError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.Load("broken", YamlExt, "./testdata/brokenYaml")
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+	as.Contains(parseErr.File, "broken.yaml")
+	as.Equal(3, parseErr.Line)
+}
+
+func Test_Loader_LoadFromDir_parseErrorWithPosition(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.LoadFromDir("hello", "./testdata/brokenJson", JsonExt)
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+	as.Contains(parseErr.File, "broken.json")
+	as.Equal(4, parseErr.Line)
+	as.Equal(1, parseErr.Column)
+}
+
+func Test_Loader_Load_decodeError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName int
+		Port       int
+		Timeout    int
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.Load("hello", YamlExt, "./testdata/loadYaml")
+
+	var decodeErr *DecodeError
+	as.True(errors.As(err, &decodeErr))
+	as.Equal("ServerName", decodeErr.Key)
+	as.Equal("HELLO_SERVER_NAME", decodeErr.EnvVar)
+	as.Equal(reflect.TypeOf(0), decodeErr.Type)
+}
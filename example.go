@@ -0,0 +1,366 @@
+package cong
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var tomlBareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// exampleNode is a key of the example config: either a group of nested keys or a leaf with a value.
+type exampleNode struct {
+	name        string
+	description string
+	value       any
+	children    []*exampleNode
+	group       bool
+}
+
+// WriteExample writes a fully populated example config for T. See Loader.WriteExample.
+func WriteExample[T any](w io.Writer, ext ConfigExtension) error {
+	return NewLoader[T]().WriteExample(w, ext)
+}
+
+// WriteExample writes a fully populated example config in the given format (YAML, TOML, JSON or dotenv).
+// Keys are named the way the loader reads them, values come from the default tags (zero values otherwise)
+// and desc tags are rendered as comments where the format supports them.
+func (loader *Loader[T]) WriteExample(w io.Writer, ext ConfigExtension) error {
+	nodes, err := exampleNodes(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch ext {
+	case YamlExt, YmlExt:
+		data, err = renderYAMLExample(nodes)
+	case JsonExt:
+		data, err = renderJSONExample(nodes)
+	case TomlExt:
+		data, err = renderTOMLExample(nodes)
+	case DotenvExt, EnvExt:
+		data, err = renderDotenvExample(nodes)
+	default:
+		return fmt.Errorf("example configs are not supported for %s", ext)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func exampleNodes(typ reflect.Type) ([]*exampleNode, error) {
+	var nodes []*exampleNode
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		if isSquashed(field) {
+			children, err := exampleNodes(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, children...)
+			continue
+		}
+
+		node := &exampleNode{name: name, description: field.Tag.Get(descTag)}
+
+		if isNestedStruct(field.Type) {
+			children, err := exampleNodes(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			node.group = true
+			node.children = children
+		} else if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+			value, err := parseTagValue(field.Type, defaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid default %q: %w", field.Name, defaultValue, err)
+			}
+			node.value = value
+		} else {
+			node.value = exampleZeroValue(field.Type)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func exampleZeroValue(typ reflect.Type) any {
+	if inner, ok := optionalInner(typ); ok {
+		return exampleZeroValue(inner)
+	}
+
+	if typ == durationType {
+		return "0s"
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return exampleZeroValue(typ.Elem())
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int64(0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint64(0)
+	case reflect.Float32, reflect.Float64:
+		return float64(0)
+	case reflect.String:
+		return ""
+	case reflect.Slice, reflect.Array:
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	default:
+		return nil
+	}
+}
+
+func renderYAMLExample(nodes []*exampleNode) ([]byte, error) {
+	root, err := yamlExampleMapping(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return []byte(sb.String()), nil
+}
+
+func yamlExampleMapping(nodes []*exampleNode) (*yaml.Node, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for _, node := range nodes {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: node.name, HeadComment: node.description}
+
+		var value *yaml.Node
+		var err error
+		if node.group {
+			value, err = yamlExampleMapping(node.children)
+		} else {
+			value = new(yaml.Node)
+			err = value.Encode(node.value)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		mapping.Content = append(mapping.Content, key, value)
+	}
+
+	return mapping, nil
+}
+
+func renderJSONExample(nodes []*exampleNode) ([]byte, error) {
+	var sb strings.Builder
+	if err := writeJSONExampleObject(&sb, nodes, ""); err != nil {
+		return nil, err
+	}
+	sb.WriteString("\n")
+
+	return []byte(sb.String()), nil
+}
+
+// writeJSONExampleObject writes the nodes as a JSON object keeping the struct field order, which encoding/json would sort.
+func writeJSONExampleObject(sb *strings.Builder, nodes []*exampleNode, indent string) error {
+	if len(nodes) == 0 {
+		sb.WriteString("{}")
+		return nil
+	}
+
+	sb.WriteString("{\n")
+	for i, node := range nodes {
+		key, _ := json.Marshal(node.name)
+		_, _ = fmt.Fprintf(sb, "%s  %s: ", indent, key)
+
+		if node.group {
+			if err := writeJSONExampleObject(sb, node.children, indent+"  "); err != nil {
+				return err
+			}
+		} else {
+			value, err := json.MarshalIndent(node.value, indent+"  ", "  ")
+			if err != nil {
+				return err
+			}
+			sb.Write(value)
+		}
+
+		if i < len(nodes)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent + "}")
+
+	return nil
+}
+
+func renderTOMLExample(nodes []*exampleNode) ([]byte, error) {
+	var sb strings.Builder
+	if err := writeTOMLExampleTable(&sb, nodes, ""); err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.TrimLeft(sb.String(), "\n")), nil
+}
+
+// writeTOMLExampleTable writes the leaves of a table first, as TOML requires, then every nested table.
+func writeTOMLExampleTable(sb *strings.Builder, nodes []*exampleNode, path string) error {
+	for _, node := range nodes {
+		if node.group {
+			continue
+		}
+
+		writeCommentLines(sb, node.description, "# ")
+
+		if node.value == nil {
+			_, _ = fmt.Fprintf(sb, "# %s =\n", tomlKey(node.name))
+			continue
+		}
+
+		value, err := tomlValue(node.value)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", joinKey(path, node.name), err)
+		}
+		_, _ = fmt.Fprintf(sb, "%s = %s\n", tomlKey(node.name), value)
+	}
+
+	for _, node := range nodes {
+		if !node.group {
+			continue
+		}
+
+		tablePath := joinKey(path, tomlKey(node.name))
+
+		sb.WriteString("\n")
+		writeCommentLines(sb, node.description, "# ")
+		_, _ = fmt.Fprintf(sb, "[%s]\n", tablePath)
+
+		if err := writeTOMLExampleTable(sb, node.children, tablePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func tomlKey(name string) string {
+	if tomlBareKeyRegexp.MatchString(name) {
+		return name
+	}
+
+	key, _ := json.Marshal(name)
+	return string(key)
+}
+
+func tomlValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		// JSON strings are valid TOML basic strings
+		quoted, err := json.Marshal(v)
+		return string(quoted), err
+	case bool, int64, uint64:
+		return fmt.Sprint(v), nil
+	case float64:
+		formatted := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(formatted, ".eE") {
+			formatted += ".0"
+		}
+		return formatted, nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			formatted, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, formatted)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		return "", fmt.Errorf("non-empty maps are not supported")
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+func renderDotenvExample(nodes []*exampleNode) ([]byte, error) {
+	var sb strings.Builder
+	writeDotenvExample(&sb, nodes, "")
+
+	return []byte(sb.String()), nil
+}
+
+func writeDotenvExample(sb *strings.Builder, nodes []*exampleNode, prefix string) {
+	for _, node := range nodes {
+		key := joinKey(prefix, node.name)
+
+		if node.group {
+			writeDotenvExample(sb, node.children, key)
+			continue
+		}
+
+		writeCommentLines(sb, node.description, "# ")
+
+		switch value := node.value.(type) {
+		case nil, map[string]any:
+			// dotenv has no syntax for maps and nulls, leave a hint for the user
+			_, _ = fmt.Fprintf(sb, "# %s=\n", key)
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			_, _ = fmt.Fprintf(sb, "%s=%s\n", key, dotenvValue(strings.Join(items, ",")))
+		default:
+			_, _ = fmt.Fprintf(sb, "%s=%s\n", key, dotenvValue(fmt.Sprint(value)))
+		}
+	}
+}
+
+func dotenvValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t#'\"\\$") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
+func writeCommentLines(sb *strings.Builder, comment string, prefix string) {
+	if comment == "" {
+		return
+	}
+
+	for _, line := range strings.Split(comment, "\n") {
+		sb.WriteString(prefix + line + "\n")
+	}
+}
@@ -0,0 +1,123 @@
+package cong
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exampleTestServer struct {
+	Host    string        `mapstructure:"host" default:"0.0.0.0" desc:"Listen host"`
+	Port    int           `mapstructure:"port" default:"8080"`
+	Timeout time.Duration `mapstructure:"timeout" default:"10s"`
+}
+
+type exampleTestConfig struct {
+	Name    string            `mapstructure:"name" desc:"Application name"`
+	Ratio   float64           `mapstructure:"ratio" default:"0.5"`
+	Debug   bool              `mapstructure:"debug"`
+	Tags    []string          `mapstructure:"tags" default:"a,b"`
+	Headers map[string]string `mapstructure:"headers"`
+	Server  exampleTestServer `mapstructure:"server" desc:"HTTP server"`
+}
+
+func Test_WriteExample(t *testing.T) {
+	tests := []struct {
+		ext      ConfigExtension
+		expected string
+	}{
+		{
+			ext: YamlExt,
+			expected: `# Application name
+name: ""
+ratio: 0.5
+debug: false
+tags:
+  - a
+  - b
+headers: {}
+# HTTP server
+server:
+  # Listen host
+  host: 0.0.0.0
+  port: 8080
+  timeout: 10s
+`,
+		},
+		{
+			ext: JsonExt,
+			expected: `{
+  "name": "",
+  "ratio": 0.5,
+  "debug": false,
+  "tags": [
+    "a",
+    "b"
+  ],
+  "headers": {},
+  "server": {
+    "host": "0.0.0.0",
+    "port": 8080,
+    "timeout": "10s"
+  }
+}
+`,
+		},
+		{
+			ext: TomlExt,
+			expected: `# Application name
+name = ""
+ratio = 0.5
+debug = false
+tags = ["a", "b"]
+headers = {}
+
+# HTTP server
+[server]
+# Listen host
+host = "0.0.0.0"
+port = 8080
+timeout = "10s"
+`,
+		},
+		{
+			ext: DotenvExt,
+			expected: `# Application name
+name=""
+ratio=0.5
+debug=false
+tags=a,b
+# headers=
+# Listen host
+server.host=0.0.0.0
+server.port=8080
+server.timeout=10s
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ext.String(), func(t *testing.T) {
+			as := assert.New(t)
+
+			var sb strings.Builder
+			as.Nil(WriteExample[exampleTestConfig](&sb, test.ext))
+			as.Equal(test.expected, sb.String())
+
+			config, err := NewLoader[exampleTestConfig]().LoadFromReader("", bytes.NewReader([]byte(sb.String())), test.ext)
+			as.Nil(err)
+			as.Equal(&exampleTestConfig{
+				Ratio: 0.5,
+				Tags:  []string{"a", "b"},
+				Server: exampleTestServer{
+					Host:    "0.0.0.0",
+					Port:    8080,
+					Timeout: 10 * time.Second,
+				},
+			}, config)
+		})
+	}
+}
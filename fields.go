@@ -0,0 +1,219 @@
+package cong
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	mapstructureTag = "mapstructure"
+	defaultTag      = "default"
+	descTag         = "desc"
+	requiredTag     = "required"
+	enumTag         = "enum"
+	aliasTag        = "alias"
+	deprecatedTag   = "deprecated"
+	secretTag       = "secret"
+	minTag          = "min"
+	maxTag          = "max"
+	lenTag          = "len"
+	oneofTag        = "oneof"
+	regexTag        = "regex"
+	urlTag          = "url"
+	hostnameTag     = "hostname"
+	portTag         = "port"
+	fileExistsTag   = "file_exists"
+	dirExistsTag    = "dir_exists"
+	nonemptyTag     = "nonempty"
+)
+
+// redactedValue replaces the values of secret fields in logs.
+const redactedValue = "[REDACTED]"
+
+var secretNameParts = []string{"password", "secret", "token", "apikey", "api_key", "privatekey", "private_key"}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fieldName returns the config key of a struct field: the name from its mapstructure tag or the Go field name.
+// The second result is false for fields the loader never fills (unexported or tagged with "-").
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name := field.Name
+	if tag, ok := field.Tag.Lookup(mapstructureTag); ok {
+		tagName, _, _ := strings.Cut(tag, ",")
+		if tagName == "-" {
+			return "", false
+		}
+		if tagName != "" {
+			name = tagName
+		}
+	}
+
+	return name, true
+}
+
+// isSquashed reports whether the fields of a nested struct are decoded at the level of its parent.
+func isSquashed(field reflect.StructField) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get(mapstructureTag), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "squash" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNestedStruct reports whether the loader treats a field type as a group of keys rather than a single value.
+func isNestedStruct(typ reflect.Type) bool {
+	_, optional := optionalInner(typ)
+	return typ.Kind() == reflect.Struct && !optional
+}
+
+// walkFields calls fn for every leaf field of the struct value with the dotted key the loader binds it to.
+// Nested structs are walked recursively, squashed ones at the level of their parent.
+func walkFields(val reflect.Value, prefix string, fn func(key string, field reflect.StructField, value reflect.Value) error) error {
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		if isSquashed(field) {
+			key = prefix
+		}
+
+		var err error
+		if isNestedStruct(field.Type) {
+			err = walkFields(val.Field(i), key, fn)
+		} else {
+			err = fn(key, field, val.Field(i))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func joinKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+func isRequired(field reflect.StructField) bool {
+	required, _ := strconv.ParseBool(field.Tag.Get(requiredTag))
+	return required
+}
+
+// enumValues returns the values allowed by the enum tag, or by its oneof synonym.
+func enumValues(field reflect.StructField) []string {
+	tag := field.Tag.Get(enumTag)
+	if tag == "" {
+		tag = field.Tag.Get(oneofTag)
+	}
+	if tag == "" {
+		return nil
+	}
+
+	return strings.Split(tag, ",")
+}
+
+// hasFlagTag reports whether a boolean rule tag such as url:"true" is enabled on the field.
+func hasFlagTag(field reflect.StructField, tag string) bool {
+	enabled, _ := strconv.ParseBool(field.Tag.Get(tag))
+	return enabled
+}
+
+// isSecret reports whether the value of a field must not be logged: it is tagged secret:"true"
+// or its name looks like a credential (password, secret, token, API or private key).
+func isSecret(key string, field reflect.StructField) bool {
+	if secret, err := strconv.ParseBool(field.Tag.Get(secretTag)); err == nil {
+		return secret
+	}
+
+	name := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// aliasKeys returns the old dotted keys of a renamed field. Like the keys in error messages they start at the config root.
+func aliasKeys(field reflect.StructField) []string {
+	tag := field.Tag.Get(aliasTag)
+	if tag == "" {
+		return nil
+	}
+
+	aliases := strings.Split(tag, ",")
+	for i, alias := range aliases {
+		aliases[i] = strings.TrimSpace(alias)
+	}
+
+	return aliases
+}
+
+// parseTagValue converts a default or enum tag value into the Go value matching typ,
+// so it can be rendered with its real type (e.g. 80 instead of "80").
+func parseTagValue(typ reflect.Type, value string) (any, error) {
+	if inner, ok := optionalInner(typ); ok {
+		return parseTagValue(inner, value)
+	}
+
+	if typ == durationType {
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return parseTagValue(typ.Elem(), value)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, typ.Bits())
+	case reflect.Slice, reflect.Array:
+		if value == "" {
+			return []any{}, nil
+		}
+		parts := strings.Split(value, ",")
+		items := make([]any, 0, len(parts))
+		for _, part := range parts {
+			item, err := parseTagValue(typ.Elem(), strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case reflect.String, reflect.Interface:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("tag values are not supported for type %s", typ)
+	}
+}
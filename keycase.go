@@ -0,0 +1,126 @@
+package cong
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// KeyCase selects which keys keep the case they are written with in YAML, JSON and TOML sources.
+// Viper lower-cases every key, so without it a map of HTTP headers gets "x-request-id" instead of "X-Request-ID".
+type KeyCase int
+
+const (
+	// KeyCaseLower lower-cases every key, like viper does.
+	KeyCaseLower KeyCase = iota
+	// KeyCaseMaps preserves the keys of map-typed fields, including the maps nested in their values.
+	KeyCaseMaps
+	// KeyCaseAll also preserves the keys of maps held by any-typed fields.
+	KeyCaseAll
+)
+
+// recordKeyNames remembers the original name of every key of a config source by its lower-cased dotted path.
+// Later sources overwrite the names recorded by earlier ones, like their values do.
+func recordKeyNames(state *loadState, prefix string, settings map[string]any) {
+	for name, value := range settings {
+		lowerKey := joinKey(prefix, strings.ToLower(name))
+		state.keyNames[lowerKey] = name
+
+		if nested, ok := value.(map[string]any); ok {
+			recordKeyNames(state, lowerKey, nested)
+		}
+	}
+}
+
+// recordSourceKeyNames parses a YAML, JSON or TOML source without lower-casing its keys and records their names.
+// Other formats keep viper's lower-cased keys.
+func recordSourceKeyNames(state *loadState, data []byte, ext ConfigExtension) {
+	if state.keyCase == KeyCaseLower {
+		return
+	}
+
+	settings := make(map[string]any)
+
+	var err error
+	switch ext {
+	case YamlExt, YmlExt:
+		err = yaml.Unmarshal(data, &settings)
+	case JsonExt:
+		err = json.Unmarshal(data, &settings)
+	case TomlExt:
+		err = toml.Unmarshal(data, &settings)
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	recordKeyNames(state, "", settings)
+}
+
+// restoreKeyCase renames the lower-cased map keys of the decoded config back to their recorded names.
+func restoreKeyCase(state *loadState, val reflect.Value, key string, inMap bool) {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if !val.IsNil() {
+			restoreKeyCase(state, val.Elem(), key, inMap)
+		}
+	case reflect.Struct:
+		if _, ok := optionalInner(val.Type()); ok {
+			restoreKeyCase(state, val.Field(0), key, inMap)
+			return
+		}
+
+		typ := val.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+
+			name, ok := fieldName(field)
+			if !ok {
+				continue
+			}
+
+			// recorded names are keyed by lower-cased paths, like viper's keys
+			fieldKey := joinKey(key, strings.ToLower(name))
+			if isSquashed(field) {
+				fieldKey = key
+			}
+			restoreKeyCase(state, val.Field(i), fieldKey, inMap)
+		}
+	case reflect.Map:
+		if val.IsNil() || val.Type().Key().Kind() != reflect.String {
+			return
+		}
+
+		restored := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			lowerKey := joinKey(key, strings.ToLower(iter.Key().String()))
+
+			name := iter.Key()
+			if original, ok := state.keyNames[lowerKey]; ok {
+				name = reflect.ValueOf(original).Convert(val.Type().Key())
+			}
+
+			value := reflect.New(val.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			restoreKeyCase(state, value, lowerKey, true)
+
+			restored.SetMapIndex(name, value)
+		}
+		val.Set(restored)
+	case reflect.Interface:
+		if val.IsNil() || (!inMap && state.keyCase != KeyCaseAll) {
+			return
+		}
+
+		value := reflect.New(val.Elem().Type()).Elem()
+		value.Set(val.Elem())
+		restoreKeyCase(state, value, key, true)
+		val.Set(value)
+	}
+}
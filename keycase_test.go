@@ -0,0 +1,112 @@
+package cong
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type keyCaseTestConfig struct {
+	Headers map[string]string            `mapstructure:"headers"`
+	Tenants map[string]map[string]string `mapstructure:"tenants"`
+	Extra   map[string]any               `mapstructure:"extra"`
+	Raw     any                          `mapstructure:"raw"`
+}
+
+func Test_Loader_WithPreserveKeyCase(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "hello.yaml"), `
+headers:
+  X-Request-ID: abc
+tenants:
+  TenantA:
+    Region: EU
+extra:
+  Nested:
+    KeyName: 1
+raw:
+  SomeKey: value
+`)
+
+	config, err := NewLoader[keyCaseTestConfig](WithPreserveKeyCase(KeyCaseMaps)).Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(map[string]string{"X-Request-ID": "abc"}, config.Headers)
+	as.Equal(map[string]map[string]string{"TenantA": {"Region": "EU"}}, config.Tenants)
+	as.Equal(map[string]any{"Nested": map[string]any{"KeyName": 1}}, config.Extra)
+	as.Equal(map[string]any{"somekey": "value"}, config.Raw)
+
+	config, err = NewLoader[keyCaseTestConfig](WithPreserveKeyCase(KeyCaseAll)).Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(map[string]any{"SomeKey": "value"}, config.Raw)
+
+	config, err = NewLoader[keyCaseTestConfig]().Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(map[string]string{"x-request-id": "abc"}, config.Headers)
+}
+
+func Test_Loader_WithPreserveKeyCase_fieldNames(t *testing.T) {
+	as := assert.New(t)
+
+	type Nested struct {
+		ExtraHeaders map[string]string `mapstructure:"extraHeaders"`
+	}
+	type TestConfig struct {
+		Headers     map[string]string
+		HTTPHeaders map[string]string `mapstructure:"httpHeaders"`
+		Nested      Nested
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "hello.yaml"), `
+headers:
+  X-Request-ID: abc
+httpHeaders:
+  X-A: b
+nested:
+  extraHeaders:
+    X-Trace: on
+`)
+
+	config, err := NewLoader[TestConfig](WithPreserveKeyCase(KeyCaseMaps)).Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(map[string]string{"X-Request-ID": "abc"}, config.Headers)
+	as.Equal(map[string]string{"X-A": "b"}, config.HTTPHeaders)
+	as.Equal(map[string]string{"X-Trace": "on"}, config.Nested.ExtraHeaders)
+}
+
+func Test_Loader_WithPreserveKeyCase_merged(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.json"), `{"headers": {"X-Request-ID": "abc", "Accept": "*/*"}}`)
+	writeTestFile(t, filepath.Join(dir, "b.json"), `{"headers": {"x-request-id": "def"}}`)
+
+	loader := NewLoader[keyCaseTestConfig](WithPreserveKeyCase(KeyCaseMaps))
+
+	config, err := loader.LoadFromDir("hello", dir, JsonExt)
+
+	as.Nil(err)
+	as.Equal(map[string]string{"x-request-id": "def", "Accept": "*/*"}, config.Headers)
+
+	fsys := fstest.MapFS{
+		"config/hello.toml": {Data: []byte("[headers]\nX-Api-Version = \"2\"\n")},
+	}
+
+	config, err = loader.LoadFromFS("hello", fsys, "config", TomlExt)
+
+	as.Nil(err)
+	as.Equal(map[string]string{"X-Api-Version": "2"}, config.Headers)
+
+	config, err = loader.LoadFromMap("hello", map[string]any{"headers": map[string]any{"Content-Type": "json"}})
+
+	as.Nil(err)
+	as.Equal(map[string]string{"Content-Type": "json"}, config.Headers)
+}
@@ -2,17 +2,25 @@ package cong
 
 import (
 	"embed"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/spf13/viper"
 )
 
+const (
+	readerSourceName = "<reader>"
+	mapSourceName    = "map"
+)
+
 var defaultConfigPaths = []string{
 	".",
 	"./config",
@@ -20,233 +28,396 @@ var defaultConfigPaths = []string{
 }
 
 type Loader[T any] struct {
-	viper *viper.Viper
+	options options
+
+	mu       sync.Mutex
+	metadata Metadata
+	// envValues holds the loaded values of the keys set by environment variables, which Save leaves out
+	envValues map[string]any
+}
+
+type boundField struct {
+	key        string
+	envVar     string
+	envVars    []string
+	typ        reflect.Type
+	aliases    []string
+	deprecated string
+	secret     bool
+}
+
+// loadState holds everything a single Load* call builds up, so consecutive calls never leak into each other.
+// Config sources are merged into fileSettings first, so the raw merged map can be migrated
+// before it is merged into viper on top of defaults and under env.
+type loadState struct {
+	viper           *viper.Viper
+	fileSettings    map[string]any
+	mergeStrategies map[string]mergeStrategy
+	configFiles     []configFile
+	boundFields     map[string]boundField
+	envPrefix       string
+	lookupEnv       envLookup
+	sources         map[string]Source
+	fileSources     map[string]Source
+	keyCase         KeyCase
+	keyNames        map[string]string
+	profile         string
+	profileKey      string
 }
 
-func NewLoader[T any]() *Loader[T] {
+func NewLoader[T any](opts ...Option) *Loader[T] {
 	return &Loader[T]{
-		viper: viper.New(),
+		options: newOptions(opts),
 	}
 }
 
 func (loader *Loader[T]) Load(projectName string, ext ConfigExtension, configPaths ...string) (*T, error) {
-	config := new(T)
+	return loader.load(projectName, func(state *loadState) error {
+		configsPaths, fileExt, err := loader.findConfigFiles(state, projectName, ext, configPaths)
+		if err != nil {
+			return err
+		}
 
-	loader.setDefaultSettings(projectName)
+		return loader.loadConfigFilesByPaths(state, configsPaths, fileExt)
+	})
+}
 
-	err := loader.bindSnakeCaseParams(config, "", projectName)
-	if err != nil {
-		return nil, err
-	}
+func (loader *Loader[T]) LoadFromDir(projectName string, path string, ext ConfigExtension) (*T, error) {
+	return loader.load(projectName, func(state *loadState) error {
+		configsPaths, err := loader.findConfigFilesInDir(path, ext)
+		if err != nil {
+			return err
+		}
 
-	loader.viper.SetConfigName(projectName)
-	loader.viper.SetConfigType(ext.String())
+		return loader.loadConfigFilesByPaths(state, configsPaths, ext)
+	})
+}
 
-	loader.loadConfigPaths(configPaths)
+func (loader *Loader[T]) LoadFromEmbedFS(projectName string, dir embed.FS, ext ConfigExtension) (*T, error) {
+	return loader.LoadFromEmbedFSByPath(projectName, dir, ".", ext)
+}
 
-	err = loader.viper.ReadInConfig()
-	if err != nil {
-		return nil, err
-	}
+func (loader *Loader[T]) LoadFromEmbedFSByPath(
+	projectName string,
+	dir embed.FS,
+	path string,
+	ext ConfigExtension,
+) (*T, error) {
+	return loader.LoadFromFS(projectName, dir, path, ext)
+}
 
-	err = loader.viper.Unmarshal(config)
-	if err != nil {
-		return nil, err
-	}
+// LoadFromFS merges every config file with the given extension under path in fsys, in lexical order,
+// like LoadFromDir does on disk. Any fs.FS works, e.g. an embed.FS or an in-memory fstest.MapFS in tests.
+func (loader *Loader[T]) LoadFromFS(projectName string, fsys fs.FS, path string, ext ConfigExtension) (*T, error) {
+	return loader.load(projectName, func(state *loadState) error {
+		configsPaths, err := loader.findConfigFilesInFS(path, fsys, ext)
+		if err != nil {
+			return err
+		}
 
-	return config, nil
+		return loader.loadConfigFilesFromFSByPaths(state, configsPaths, fsys, ext)
+	})
 }
 
-func (loader *Loader[T]) LoadFromDir(projectName string, path string, ext ConfigExtension) (*T, error) {
-	config := new(T)
+// LoadFromReader fills config from content read from r in the given format, e.g. a YAML blob fetched from an API.
+func (loader *Loader[T]) LoadFromReader(projectName string, r io.Reader, ext ConfigExtension) (*T, error) {
+	return loader.load(projectName, func(state *loadState) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
 
-	loader.setDefaultSettings(projectName)
+		if err := loader.mergeConfigSource(state, readerSourceName, data, ext, false); err != nil {
+			return err
+		}
+		loader.options.logger.Debug("config merged", "source", readerSourceName, "format", ext.String())
 
-	err := loader.bindSnakeCaseParams(config, "", projectName)
-	if err != nil {
-		return nil, err
-	}
+		return nil
+	})
+}
 
-	configsPaths, err := loader.findConfigFilesInDir(path, ext)
-	if err != nil {
-		return nil, err
-	}
+// LoadFromMap fills config from a map built in code, e.g. map[string]any{"server": map[string]any{"port": 80}}.
+func (loader *Loader[T]) LoadFromMap(projectName string, m map[string]any) (*T, error) {
+	return loader.load(projectName, func(state *loadState) error {
+		loader.options.logger.Debug("config merged", "source", mapSourceName)
+		if state.keyCase != KeyCaseLower {
+			recordKeyNames(state, "", m)
+		}
 
-	err = loader.loadConfigFilesByPaths(configsPaths, ext)
-	if err != nil {
-		return nil, err
-	}
+		directives := make(mergeDirectives)
+		mapMergeDirectives(m, "", directives)
 
-	err = loader.viper.Unmarshal(config)
-	if err != nil {
-		return nil, err
-	}
+		// viper lower-cases the keys and drops the nil values
+		v := viper.New()
+		if err := v.MergeConfigMap(copyMap(m)); err != nil {
+			return err
+		}
+		settings := v.AllSettings()
 
-	return config, nil
+		if _, err := loader.migrate(settings); err != nil {
+			return fmt.Errorf("%s: %w", mapSourceName, err)
+		}
+
+		recordFileSources(state, mapSourceName, settings)
+		mergeIntoFileLayer(state, settings, directives)
+
+		return nil
+	})
 }
 
-func (loader *Loader[T]) LoadFromEmbedFS(projectName string, dir embed.FS, ext ConfigExtension) (*T, error) {
-	config := new(T)
+// LoadFromEnv fills config only from environment variables using snake_case bindings with the given project prefix.
+// It is useful for Twelve-Factor applications where configuration is expected to come from the environment.
+func (loader *Loader[T]) LoadFromEnv(projectName string) (*T, error) {
+	return loader.load(projectName, func(*loadState) error {
+		return nil
+	})
+}
 
-	loader.setDefaultSettings(projectName)
+// load runs the pipeline shared by every Load* method on a fresh loadState:
+// env bindings first, then the sources added by readConfig, the env layer on top, then unmarshalling into a new T.
+func (loader *Loader[T]) load(projectName string, readConfig func(state *loadState) error) (*T, error) {
+	config := new(T)
 
-	err := loader.bindSnakeCaseParams(config, "", projectName)
+	state, err := loader.newLoadState(projectName)
 	if err != nil {
 		return nil, err
 	}
 
-	configsPaths, err := loader.findConfigFilesInEmbedFS(".", dir, ext)
+	err = loader.bindSnakeCaseParams(state, config, projectName)
 	if err != nil {
 		return nil, err
 	}
 
-	err = loader.loadConfigFilesFromEmbedFsByPaths(configsPaths, dir, ext)
+	err = loader.setProgrammaticDefaults(state)
 	if err != nil {
 		return nil, err
 	}
 
-	err = loader.viper.Unmarshal(config)
+	err = readConfig(state)
 	if err != nil {
 		return nil, err
 	}
 
-	return config, nil
-}
-
-func (loader *Loader[T]) LoadFromEmbedFSByPath(
-	projectName string,
-	dir embed.FS,
-	path string,
-	ext ConfigExtension,
-) (*T, error) {
-	config := new(T)
-
-	loader.setDefaultSettings(projectName)
-
-	err := loader.bindSnakeCaseParams(config, "", projectName)
+	err = loader.mergeFileLayer(state)
 	if err != nil {
 		return nil, err
 	}
 
-	configsPaths, err := loader.findConfigFilesInEmbedFS(path, dir, ext)
-	if err != nil {
-		return nil, err
-	}
+	loader.applyEnv(state)
 
-	err = loader.loadConfigFilesFromEmbedFsByPaths(configsPaths, dir, ext)
+	err = loader.decode(state, config)
 	if err != nil {
 		return nil, err
 	}
 
-	err = loader.viper.Unmarshal(config)
-	if err != nil {
-		return nil, err
-	}
+	loader.saveMetadata(state, config)
 
 	return config, nil
 }
 
-// LoadFromEnv fills config only from environment variables using snake_case bindings with the given project prefix.
-// It is useful for Twelve-Factor applications where configuration is expected to come from the environment.
-func (loader *Loader[T]) LoadFromEnv(projectName string) (*T, error) {
-	config := new(T)
+// mergeFileLayer resolves renamed keys of the merged config sources and puts them between defaults and env.
+func (loader *Loader[T]) mergeFileLayer(state *loadState) error {
+	settings := state.fileSettings
 
-	loader.setDefaultSettings(projectName)
+	loader.resolveAliases(state, settings, fileLayerSource(state))
 
-	if err := loader.bindSnakeCaseParams(config, "", projectName); err != nil {
-		return nil, err
-	}
+	for lowerKey := range state.boundFields {
+		if _, ok := lookupSetting(settings, lowerKey); !ok {
+			continue
+		}
 
-	if err := loader.viper.Unmarshal(config); err != nil {
-		return nil, err
+		source, ok := state.fileSources[lowerKey]
+		if !ok {
+			source = Source{Kind: SourceConfig, Name: fileLayerSource(state)}
+		}
+		state.sources[lowerKey] = source
 	}
 
-	return config, nil
+	return state.viper.MergeConfigMap(settings)
 }
 
-func (loader *Loader[T]) setDefaultSettings(projectName string) {
-	loader.viper.AutomaticEnv()
-	loader.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	loader.viper.SetEnvPrefix(projectName)
+func (loader *Loader[T]) decode(state *loadState, config *T) error {
+	err := state.viper.Unmarshal(config, loader.decoderOptions()...)
+	if err != nil {
+		return loader.newDecodeError(state, err)
+	}
+
+	if state.keyCase != KeyCaseLower {
+		restoreKeyCase(state, reflect.ValueOf(config).Elem(), "", false)
+	}
+
+	setOptionalSources(state, reflect.ValueOf(config).Elem())
+
+	return loader.validate(state, config)
 }
 
-func (loader *Loader[T]) bindSnakeCaseParams(config interface{}, prefix string, envPrefix string) error {
-	refVal := reflect.ValueOf(config)
-	if refVal.Kind() == reflect.Ptr {
-		refVal = refVal.Elem()
+// newLoadState creates the state for a single load. An empty projectName disables environment variables,
+// so only defaults and the explicitly read sources are used.
+func (loader *Loader[T]) newLoadState(projectName string) (*loadState, error) {
+	state := &loadState{
+		viper:           viper.New(),
+		fileSettings:    make(map[string]any),
+		mergeStrategies: make(map[string]mergeStrategy),
+		boundFields:     make(map[string]boundField),
+		envPrefix:       projectName,
+		sources:         make(map[string]Source),
+		fileSources:     make(map[string]Source),
+		keyCase:         loader.options.keyCase,
+		keyNames:        make(map[string]string),
+		profile:         loader.options.profile,
+		profileKey:      strings.ToLower(loader.options.profileKey),
 	}
-	refType := refVal.Type()
 
-	for i := 0; i < refVal.NumField(); i++ {
-		field := refType.Field(i)
-		name := field.Name
+	if projectName != "" {
+		lookupEnv, err := loader.newEnvLookup()
+		if err != nil {
+			return nil, err
+		}
+		state.lookupEnv = lookupEnv
+	}
 
-		tag, hasTag := field.Tag.Lookup("mapstructure")
-		if hasTag {
-			name = tag
+	return state, nil
+}
+
+func (loader *Loader[T]) bindSnakeCaseParams(state *loadState, config *T, envPrefix string) error {
+	return walkFields(reflect.ValueOf(config).Elem(), "", func(key string, field reflect.StructField, _ reflect.Value) error {
+		bound := boundField{
+			key:        key,
+			typ:        field.Type,
+			aliases:    aliasKeys(field),
+			deprecated: field.Tag.Get(deprecatedTag),
+			secret:     isSecret(key, field),
 		}
 
-		fullName := name
-		if prefix != "" {
-			fullName = prefix + "." + name
+		if envPrefix != "" {
+			bound.envVar = loader.envVarName(envPrefix, key)
+			bound.envVars = loader.boundEnvVarNames(envPrefix, bound)
 		}
+		state.boundFields[strings.ToLower(key)] = bound
 
-		if field.Type.Kind() == reflect.Struct {
-			if err := loader.bindSnakeCaseParams(refVal.Field(i).Interface(), fullName, envPrefix); err != nil {
-				return err
-			}
-			continue
+		strategy, err := parseMergeTag(key, field)
+		if err != nil {
+			return err
+		}
+		if strategy.kind != "" {
+			state.mergeStrategies[strings.ToLower(key)] = strategy
 		}
 
-		envVarName := strings.ToUpper(envPrefix + "_" + loader.toSnakeCase(fullName))
-		if err := loader.viper.BindEnv(fullName, envVarName); err != nil {
-			return fmt.Errorf("failed to bind environment variable for %s: %w", fullName, err)
+		if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+			state.viper.SetDefault(key, defaultValue)
+			state.sources[strings.ToLower(key)] = Source{Kind: SourceDefault}
 		}
-	}
-	return nil
+
+		return nil
+	})
+}
+
+func (loader *Loader[T]) envVarName(envPrefix string, key string) string {
+	return strings.ToUpper(envPrefix + "_" + loader.toSnakeCase(key))
 }
 
-func (loader *Loader[T]) loadConfigFilesByPaths(configsPaths []string, ext ConfigExtension) error {
-	for _, path := range configsPaths {
-		loader.setConfigItemInfo(path, ext)
-		err := loader.viper.MergeInConfig()
+func (loader *Loader[T]) loadConfigFilesByPaths(state *loadState, configsPaths []string, ext ConfigExtension) error {
+	for i, path := range configsPaths {
+		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
+
+		err = loader.mergeConfigSource(state, path, data, ext, true)
+		if err != nil {
+			return err
+		}
+		loader.options.logger.Debug("config file merged", "file", path, "order", i+1, "of", len(configsPaths))
+
+		state.configFiles = append(state.configFiles, configFile{path: path, ext: ext})
 	}
 
 	return nil
 }
 
-func (loader *Loader[T]) loadConfigFilesFromEmbedFsByPaths(configsPaths []string, dir embed.FS, ext ConfigExtension) error {
-	for _, path := range configsPaths {
-		data, err := dir.ReadFile(path)
+func (loader *Loader[T]) loadConfigFilesFromFSByPaths(
+	state *loadState,
+	configsPaths []string,
+	fsys fs.FS,
+	ext ConfigExtension,
+) error {
+	for i, path := range configsPaths {
+		data, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return err
 		}
 
-		loader.setConfigItemInfo(path, ext)
-		err = loader.viper.MergeConfig(strings.NewReader(string(data)))
+		err = loader.mergeConfigSource(state, path, data, ext, false)
+		if err != nil {
+			return err
+		}
+		loader.options.logger.Debug("config file merged", "file", path, "fs", true, "order", i+1, "of", len(configsPaths))
+	}
+
+	return nil
+}
+
+// profileSelector selects the documents of multi-document YAML files by the active profile.
+func (state *loadState) profileSelector() profileSelector {
+	_, bound := state.boundFields[state.profileKey]
+	return profileSelector{key: state.profileKey, profile: state.profile, bound: bound}
+}
+
+// mergeConfigSource parses a config source, migrates each of its layers on its own and merges them into the file layer,
+// recording which keys they set. Upgraded files are written back when the source is a file on disk.
+func (loader *Loader[T]) mergeConfigSource(state *loadState, name string, data []byte, ext ConfigExtension, file bool) error {
+	documents, err := parseConfigSource(name, data, ext, state.profileSelector())
+	if err != nil {
+		return err
+	}
+
+	upgraded := make([]bool, len(documents))
+	var anyUpgraded bool
+	for i, document := range documents {
+		if !document.selected {
+			continue
+		}
+
+		upgraded[i], err = loader.migrate(document.settings)
 		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		anyUpgraded = anyUpgraded || upgraded[i]
+	}
+
+	if anyUpgraded && loader.options.writeUpgradedFile {
+		if !file {
+			loader.options.logger.Warn("upgraded config not written, it was not loaded from a file", "source", name)
+		} else if err := loader.writeUpgradedFile(state, name, data, ext, documents, upgraded); err != nil {
 			return err
 		}
 	}
 
+	for _, document := range documents {
+		if !document.selected {
+			continue
+		}
+
+		recordFileSources(state, name, document.settings)
+		recordSourceKeyNames(state, document.data, ext)
+		mergeIntoFileLayer(state, document.settings, document.directives)
+	}
+
 	return nil
 }
 
-func (loader *Loader[T]) setConfigItemInfo(path string, ext ConfigExtension) {
-	dirPath, file := filepath.Split(path)
-	configName := file[:len(file)-len(filepath.Ext(file))]
-	loader.viper.SetConfigName(configName)
-	loader.viper.SetConfigType(ext.String())
-	loader.viper.AddConfigPath(dirPath)
+func recordFileSources(state *loadState, name string, settings map[string]any) {
+	for lowerKey := range state.boundFields {
+		if _, ok := lookupSetting(settings, lowerKey); ok {
+			state.fileSources[lowerKey] = Source{Kind: SourceConfig, Name: name}
+		}
+	}
 }
 
-func (loader *Loader[T]) findConfigFilesInEmbedFS(path string, dir embed.FS, ext ConfigExtension) ([]string, error) {
+func (loader *Loader[T]) findConfigFilesInFS(path string, fsys fs.FS, ext ConfigExtension) ([]string, error) {
 	configsPaths := make([]string, 0)
 
-	err := fs.WalkDir(dir, path, func(path string, info fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, path, func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -257,9 +428,13 @@ func (loader *Loader[T]) findConfigFilesInEmbedFS(path string, dir embed.FS, ext
 
 		return nil
 	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &NotFoundError{Ext: ext, Paths: []string{path}, Err: err}
+	}
 	if err != nil {
 		return nil, err
 	}
+	loader.options.logger.Debug("config files found", "dir", path, "fs", true, "files", configsPaths)
 
 	return configsPaths, nil
 }
@@ -283,26 +458,17 @@ func (loader *Loader[T]) findConfigFilesInDir(path string, ext ConfigExtension)
 
 		return nil
 	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &NotFoundError{Ext: ext, Paths: []string{absolutePath}, Err: err}
+	}
 	if err != nil {
 		return nil, err
 	}
+	loader.options.logger.Debug("config files found", "dir", absolutePath, "files", configsPaths)
 
 	return configsPaths, nil
 }
 
-func (loader *Loader[T]) loadConfigPaths(configPaths []string) {
-	var paths []string
-	if len(configPaths) != 0 {
-		paths = configPaths
-	} else {
-		paths = defaultConfigPaths
-	}
-
-	for _, path := range paths {
-		loader.viper.AddConfigPath(path)
-	}
-}
-
 func (loader *Loader[T]) toSnakeCase(s string) string {
 	var res = make([]rune, 0, len(s))
 	var p = '_'
@@ -323,3 +489,16 @@ func (loader *Loader[T]) toSnakeCase(s string) string {
 	}
 	return string(res)
 }
+
+// copyMap deep-copies nested maps so viper can lower-case keys without mutating the caller's map.
+func copyMap(m map[string]any) map[string]any {
+	res := make(map[string]any, len(m))
+	for key, value := range m {
+		if nested, ok := value.(map[string]any); ok {
+			value = copyMap(nested)
+		}
+		res[key] = value
+	}
+
+	return res
+}
@@ -2,7 +2,11 @@ package cong
 
 import (
 	"embed"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -220,6 +224,29 @@ func Test_Loader_LoadFromEmbed(t *testing.T) {
 	})
 }
 
+func Test_Loader_LoadFromFS(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Name string
+		Port int
+	}
+
+	fsys := fstest.MapFS{
+		"config/a.yaml":    {Data: []byte("name: first\nport: 80\n")},
+		"config/b.yaml":    {Data: []byte("port: 8080\n")},
+		"config/c.json":    {Data: []byte(`{"name": "ignored"}`)},
+		"other/hello.yaml": {Data: []byte("name: ignored\n")},
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	config, err := loader.LoadFromFS("hello", fsys, "config", YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{Name: "first", Port: 8080}, config)
+}
+
 func Test_Loader_LoadFromEmbed_withEnvReplace(t *testing.T) {
 	as := assert.New(t)
 
@@ -553,3 +580,174 @@ func TestLoader_Load_HugeFile(t *testing.T) {
 		},
 	})
 }
+
+func Test_Loader_Load_withOptionalFile(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("MISSING_SERVER_NAME", "PAM-PAM")
+	t.Setenv("MISSING_PORT", "8080")
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	loader := NewLoader[TestConfig](WithOptionalFile())
+
+	config, err := loader.Load("missing", YamlExt, "./testdata/loadYaml")
+
+	as.Nil(err)
+	as.Equal(&TestConfig{
+		ServerName: "PAM-PAM",
+		Port:       8080,
+	}, config)
+}
+
+func Test_Loader_Load_withOptionalFileMalformed(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig](WithOptionalFile())
+
+	_, err := loader.Load("broken", YamlExt, "./testdata/brokenYaml")
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+}
+
+func Test_Loader_LoadFromReader(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("HELLO_PORT", "8080")
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	config, err := loader.LoadFromReader("hello", strings.NewReader("serverName: HelloWorld\nport: 80\ntimeout: 20\n"), YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{
+		ServerName: "HelloWorld",
+		Port:       8080,
+		Timeout:    20,
+	}, config)
+}
+
+func Test_Loader_LoadFromReader_parseError(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	_, err := loader.LoadFromReader("hello", strings.NewReader("{\n  \"serverName\": }"), JsonExt)
+
+	var parseErr *ParseError
+	as.True(errors.As(err, &parseErr))
+	as.Equal(2, parseErr.Line)
+}
+
+func Test_Loader_LoadFromMap(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("HELLO_SERVER_PORT", "8080")
+
+	type Server struct {
+		Name string
+		Port int
+	}
+	type TestConfig struct {
+		Server Server
+	}
+
+	source := map[string]any{
+		"Server": map[string]any{
+			"Name": "ServerName",
+			"Port": 80,
+		},
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	config, err := loader.LoadFromMap("hello", source)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{
+		Server: Server{
+			Name: "ServerName",
+			Port: 8080,
+		},
+	}, config)
+	as.Contains(source["Server"], "Name")
+}
+
+func Test_Loader_reusable(t *testing.T) {
+	as := assert.New(t)
+
+	type App struct {
+		Name string
+	}
+	type Server struct {
+		Name string
+		Port int
+	}
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		App        App
+		Server     Server
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	config, err := loader.Load("hello", YamlExt, "./testdata/loadYaml")
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "HelloWorld", Port: 80}, config)
+
+	config, err = loader.LoadFromDir("hello", "./testdata/loadDirYaml", YamlExt)
+	as.Nil(err)
+	as.Equal(&TestConfig{
+		App:    App{Name: "HelloWorld"},
+		Server: Server{Name: "ServerName", Port: 80},
+	}, config)
+
+	config, err = loader.LoadFromMap("hello", map[string]any{"port": 1})
+	as.Nil(err)
+	as.Equal(&TestConfig{Port: 1}, config)
+}
+
+func Test_Loader_concurrent(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			config, err := loader.LoadFromMap("hello", map[string]any{"port": i})
+			as.Nil(err)
+			as.Equal(&TestConfig{Port: i}, config)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,23 @@
+package cong
+
+// logEnvOverride logs the environment variable that sets a field, with secret values redacted,
+// and warns when it is a deprecated one.
+func (loader *Loader[T]) logEnvOverride(bound boundField, envVar string, value string) {
+	if envVar != bound.envVar {
+		loader.options.logger.Warn("deprecated environment variable",
+			"env", envVar,
+			"replacement", bound.envVar,
+		)
+	} else if bound.deprecated != "" {
+		loader.options.logger.Warn("deprecated config key",
+			"key", bound.key,
+			"env", envVar,
+			"message", bound.deprecated,
+		)
+	}
+
+	if bound.secret {
+		value = redactedValue
+	}
+	loader.options.logger.Debug("env override", "key", bound.key, "env", envVar, "value", value)
+}
@@ -0,0 +1,86 @@
+package cong
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loggingTestConfig struct {
+	Name     string `mapstructure:"name"`
+	Password string `mapstructure:"password"`
+	Dsn      string `mapstructure:"dsn" secret:"true"`
+}
+
+func newDebugLogger(logs *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func Test_Loader_logging_searchPaths(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "local", "hello.yaml"), "name: local\n")
+	writeTestFile(t, filepath.Join(dir, "system", "hello.yaml"), "name: system\n")
+
+	var logs bytes.Buffer
+	loader := NewLoader[loggingTestConfig](WithMergeAllFound(), WithLogger(newDebugLogger(&logs)))
+
+	_, err := loader.Load("hello", YamlExt, filepath.Join(dir, "local"), filepath.Join(dir, "missing"), filepath.Join(dir, "system"))
+
+	as.Nil(err)
+	as.Contains(logs.String(), "path="+filepath.Join(dir, "missing")+" file="+filepath.Join(dir, "missing", "hello.yaml")+" found=false")
+	as.Contains(logs.String(), "path="+filepath.Join(dir, "local")+" file="+filepath.Join(dir, "local", "hello.yaml")+" found=true")
+	as.Contains(logs.String(), `msg="config file merged" file=`+filepath.Join(dir, "system", "hello.yaml")+" order=1 of=2")
+	as.Contains(logs.String(), `msg="config file merged" file=`+filepath.Join(dir, "local", "hello.yaml")+" order=2 of=2")
+}
+
+func Test_Loader_logging_dir(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[loggingTestConfig](WithLogger(newDebugLogger(&logs)))
+
+	_, err := loader.LoadFromDir("hello", "./testdata/check/valid", YamlExt)
+
+	as.Nil(err)
+	as.Contains(logs.String(), `msg="config files found"`)
+	as.Contains(logs.String(), "app.yaml")
+	as.Contains(logs.String(), "order=2 of=2")
+}
+
+func Test_Loader_logging_envRedacted(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("LOGGING_NAME", "hello")
+	t.Setenv("LOGGING_PASSWORD", "hunter2")
+	t.Setenv("LOGGING_DSN", "postgres://user:pass@db")
+
+	var logs bytes.Buffer
+	loader := NewLoader[loggingTestConfig](WithLogger(newDebugLogger(&logs)))
+
+	config, err := loader.LoadFromEnv("logging")
+
+	as.Nil(err)
+	as.Equal("hunter2", config.Password)
+	as.Contains(logs.String(), `msg="env override" key=name env=LOGGING_NAME value=hello`)
+	as.Contains(logs.String(), "env=LOGGING_PASSWORD value="+redactedValue)
+	as.Contains(logs.String(), "env=LOGGING_DSN value="+redactedValue)
+	as.NotContains(logs.String(), "hunter2")
+	as.NotContains(logs.String(), "postgres://")
+}
+
+func Test_Loader_logging_silentByDefault(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[loggingTestConfig](WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	_, err := loader.LoadFromDir("hello", "./testdata/check/valid", YamlExt)
+
+	as.Nil(err)
+	as.Empty(logs.String())
+}
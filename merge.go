@@ -0,0 +1,282 @@
+package cong
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	mergeTag = "merge"
+	// resetYAMLTag marks a YAML value that replaces the inherited one instead of being merged into it,
+	// or removes the inherited value when it is empty.
+	resetYAMLTag = "!reset"
+)
+
+// Merge strategies of the merge tag.
+const (
+	mergeAppend  = "append"
+	mergeReplace = "replace"
+	mergeUnion   = "union"
+	mergeByKey   = "by_key"
+)
+
+// mergeStrategy tells how the value of a key in a config source is merged with the value of the earlier sources.
+// The zero strategy merges maps key by key and replaces everything else.
+type mergeStrategy struct {
+	kind string
+	key  string
+}
+
+// mergeDirective overrides the merge strategy for a key of a single config source.
+type mergeDirective int
+
+const (
+	// mergeReset replaces the inherited value wholesale.
+	mergeReset mergeDirective = iota + 1
+	// mergeRemove deletes the inherited value, so lower layers (defaults) apply again.
+	mergeRemove
+)
+
+// mergeDirectives holds the directives of a config source by lower-cased dotted key.
+type mergeDirectives map[string]mergeDirective
+
+// parseMergeTag reads the merge tag of a field: append, replace, union or by_key:<name>.
+func parseMergeTag(key string, field reflect.StructField) (mergeStrategy, error) {
+	tag, ok := field.Tag.Lookup(mergeTag)
+	if !ok {
+		return mergeStrategy{}, nil
+	}
+
+	kind, byKey, _ := strings.Cut(tag, ":")
+	strategy := mergeStrategy{kind: kind, key: strings.ToLower(byKey)}
+
+	typ := field.Type
+	if inner, ok := optionalInner(typ); ok {
+		typ = inner
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var valid bool
+	switch kind {
+	case mergeReplace:
+		valid = byKey == "" && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map)
+	case mergeAppend, mergeUnion:
+		valid = byKey == "" && typ.Kind() == reflect.Slice
+	case mergeByKey:
+		if typ.Kind() == reflect.Slice {
+			elem := typ.Elem()
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			valid = byKey != "" && (elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map)
+		}
+	}
+	if !valid {
+		return mergeStrategy{}, fmt.Errorf("field %s has an invalid merge tag %q for type %s", key, tag, field.Type)
+	}
+
+	return strategy, nil
+}
+
+// sourceMergeDirectives finds the null and !reset values of a YAML or JSON config source.
+func sourceMergeDirectives(data []byte, ext ConfigExtension) mergeDirectives {
+	directives := make(mergeDirectives)
+
+	switch ext {
+	case YamlExt, YmlExt:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err == nil {
+			yamlMergeDirectives(&node, "", directives)
+		}
+	case JsonExt:
+		var settings map[string]any
+		if err := json.Unmarshal(data, &settings); err == nil {
+			mapMergeDirectives(settings, "", directives)
+		}
+	}
+
+	return directives
+}
+
+func yamlMergeDirectives(node *yaml.Node, prefix string, directives mergeDirectives) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			yamlMergeDirectives(child, prefix, directives)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := joinKey(prefix, strings.ToLower(node.Content[i].Value))
+			value := node.Content[i+1]
+
+			switch {
+			case value.Tag == resetYAMLTag && value.Kind == yaml.ScalarNode && isYAMLNull(value.Value):
+				directives[key] = mergeRemove
+			case value.Tag == resetYAMLTag:
+				directives[key] = mergeReset
+			case value.Kind == yaml.ScalarNode && value.ShortTag() == "!!null":
+				directives[key] = mergeRemove
+			case value.Kind == yaml.MappingNode:
+				yamlMergeDirectives(value, key, directives)
+			}
+		}
+	}
+}
+
+func isYAMLNull(value string) bool {
+	switch value {
+	case "", "~", "null", "Null", "NULL":
+		return true
+	default:
+		return false
+	}
+}
+
+// mapMergeDirectives removes the keys set to nil.
+func mapMergeDirectives(settings map[string]any, prefix string, directives mergeDirectives) {
+	for name, value := range settings {
+		key := joinKey(prefix, strings.ToLower(name))
+
+		switch value := value.(type) {
+		case nil:
+			directives[key] = mergeRemove
+		case map[string]any:
+			mapMergeDirectives(value, key, directives)
+		}
+	}
+}
+
+// mergeIntoFileLayer merges the settings of a config source over the sources merged before it.
+func mergeIntoFileLayer(state *loadState, settings map[string]any, directives mergeDirectives) {
+	mergeLayer(state.mergeStrategies, state.fileSettings, settings, directives)
+}
+
+// mergeLayer merges a layer of settings into dst, applying its directives first.
+func mergeLayer(strategies map[string]mergeStrategy, dst, settings map[string]any, directives mergeDirectives) {
+	for key, directive := range directives {
+		if directive == mergeRemove {
+			deleteSetting(dst, key)
+			deleteSetting(settings, key)
+		}
+	}
+
+	mergeSettings(strategies, dst, settings, "", directives)
+}
+
+// mergeSettings merges src into dst: maps key by key and other values according to the merge strategy of their key.
+func mergeSettings(strategies map[string]mergeStrategy, dst, src map[string]any, prefix string, directives mergeDirectives) {
+	for name, value := range src {
+		key := joinKey(prefix, name)
+
+		existing, ok := dst[name]
+		if !ok || directives[key] == mergeReset {
+			dst[name] = value
+			continue
+		}
+
+		strategy := strategies[key]
+
+		if srcMap, ok := value.(map[string]any); ok {
+			if dstMap, ok := existing.(map[string]any); ok && strategy.kind != mergeReplace {
+				mergeSettings(strategies, dstMap, srcMap, key, directives)
+				continue
+			}
+		}
+
+		dstItems, dstOk := anySlice(existing)
+		srcItems, srcOk := anySlice(value)
+		if dstOk && srcOk {
+			dst[name] = mergeSlices(strategy, dstItems, srcItems)
+			continue
+		}
+
+		dst[name] = value
+	}
+}
+
+func mergeSlices(strategy mergeStrategy, dst, src []any) []any {
+	dst = append([]any(nil), dst...)
+
+	switch strategy.kind {
+	case mergeAppend:
+		return append(dst, src...)
+	case mergeUnion:
+		for _, item := range src {
+			if !containsItem(dst, item) {
+				dst = append(dst, item)
+			}
+		}
+		return dst
+	case mergeByKey:
+		for _, item := range src {
+			dst = mergeItemByKey(dst, item, strategy.key)
+		}
+		return dst
+	default:
+		return src
+	}
+}
+
+// mergeItemByKey merges an item into the item of dst with the same value of the key, or appends it.
+func mergeItemByKey(dst []any, item any, key string) []any {
+	itemMap, ok := item.(map[string]any)
+	if !ok {
+		return append(dst, item)
+	}
+
+	id, ok := lookupFold(itemMap, key)
+	if !ok {
+		return append(dst, item)
+	}
+
+	for i, existing := range dst {
+		existingMap, ok := existing.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if existingID, ok := lookupFold(existingMap, key); ok && fmt.Sprint(existingID) == fmt.Sprint(id) {
+			merged := copyMap(existingMap)
+			mergeSettings(nil, merged, itemMap, "", nil)
+			dst[i] = merged
+			return dst
+		}
+	}
+
+	return append(dst, item)
+}
+
+func containsItem(items []any, item any) bool {
+	for _, existing := range items {
+		if reflect.DeepEqual(existing, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anySlice converts a list of any element type, e.g. a []string passed to LoadFromMap, to []any.
+func anySlice(value any) ([]any, bool) {
+	if items, ok := value.([]any); ok {
+		return items, true
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	items := make([]any, val.Len())
+	for i := range items {
+		items[i] = val.Index(i).Interface()
+	}
+
+	return items, true
+}
@@ -0,0 +1,135 @@
+package cong
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeTestUpstream struct {
+	Name   string `mapstructure:"name"`
+	URL    string `mapstructure:"url"`
+	Weight int    `mapstructure:"weight"`
+}
+
+type mergeTestConfig struct {
+	Name      string              `mapstructure:"name" default:"app"`
+	Plugins   []string            `mapstructure:"plugins" merge:"append"`
+	Hosts     []string            `mapstructure:"hosts" merge:"union"`
+	Ports     []int               `mapstructure:"ports"`
+	Upstreams []mergeTestUpstream `mapstructure:"upstreams" merge:"by_key:name"`
+	Labels    map[string]string   `mapstructure:"labels" merge:"replace"`
+	Headers   map[string]string   `mapstructure:"headers"`
+}
+
+func Test_Loader_mergeStrategies(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "1-base.yaml"), `
+name: base
+plugins: [auth]
+hosts: [a, b]
+ports: [80]
+upstreams:
+  - name: api
+    url: http://api
+    weight: 1
+  - name: web
+    url: http://web
+labels: {team: core, tier: base}
+headers: {accept: json, trace: "on"}
+`)
+	writeTestFile(t, filepath.Join(dir, "2-prod.yaml"), `
+plugins: [metrics]
+hosts: [b, c]
+ports: [443]
+upstreams:
+  - name: api
+    weight: 5
+  - name: admin
+    url: http://admin
+labels: {tier: prod}
+headers: {trace: off}
+`)
+
+	config, err := NewLoader[mergeTestConfig]().LoadFromDir("hello", dir, YamlExt)
+
+	as.Nil(err)
+	as.Equal(&mergeTestConfig{
+		Name:    "base",
+		Plugins: []string{"auth", "metrics"},
+		Hosts:   []string{"a", "b", "c"},
+		Ports:   []int{443},
+		Upstreams: []mergeTestUpstream{
+			{Name: "api", URL: "http://api", Weight: 5},
+			{Name: "web", URL: "http://web"},
+			{Name: "admin", URL: "http://admin"},
+		},
+		Labels:  map[string]string{"tier": "prod"},
+		Headers: map[string]string{"accept": "json", "trace": "off"},
+	}, config)
+}
+
+func Test_Loader_mergeReset(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "1-base.yaml"), `
+name: base
+plugins: [auth]
+headers: {accept: json, trace: "on"}
+`)
+	writeTestFile(t, filepath.Join(dir, "2-prod.yaml"), `
+name: ~
+plugins: !reset [metrics]
+headers: !reset {trace: off}
+`)
+
+	loader := NewLoader[mergeTestConfig]()
+
+	config, err := loader.LoadFromDir("hello", dir, YamlExt)
+
+	as.Nil(err)
+	as.Equal("app", config.Name)
+	as.Equal([]string{"metrics"}, config.Plugins)
+	as.Equal(map[string]string{"trace": "off"}, config.Headers)
+
+	source, _ := loader.Metadata().Source("name")
+	as.Equal(Source{Kind: SourceDefault}, source)
+
+	writeTestFile(t, filepath.Join(dir, "2-prod.yaml"), "plugins: !reset\nheaders:\n  trace: !reset\n")
+
+	config, err = loader.LoadFromDir("hello", dir, YamlExt)
+
+	as.Nil(err)
+	as.Nil(config.Plugins)
+	as.Equal(map[string]string{"accept": "json"}, config.Headers)
+}
+
+func Test_Loader_mergeNullJSON(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "1-base.json"), `{"name": "base", "plugins": ["auth"]}`)
+	writeTestFile(t, filepath.Join(dir, "2-prod.json"), `{"plugins": null}`)
+
+	config, err := NewLoader[mergeTestConfig]().LoadFromDir("hello", dir, JsonExt)
+
+	as.Nil(err)
+	as.Equal("base", config.Name)
+	as.Nil(config.Plugins)
+}
+
+func Test_Loader_invalidMergeTag(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Name string `mapstructure:"name" merge:"append"`
+	}
+
+	_, err := NewLoader[TestConfig]().LoadFromMap("", map[string]any{})
+
+	as.EqualError(err, `field name has an invalid merge tag "append" for type string`)
+}
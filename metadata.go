@@ -0,0 +1,165 @@
+package cong
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// SourceKind is the kind of layer a config value came from.
+type SourceKind string
+
+const (
+	SourceDefault SourceKind = "default"
+	SourceConfig  SourceKind = "config"
+	SourceEnv     SourceKind = "env"
+)
+
+// Source tells where the value of a config key came from. Name is the file path (or "<reader>", "map")
+// for config sources, the variable name for env and empty for defaults.
+type Source struct {
+	Kind SourceKind
+	Name string
+}
+
+func (s Source) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+
+	return string(s.Kind) + " " + s.Name
+}
+
+// Metadata describes a load: the dotted key of every field that was set and where its value came from.
+type Metadata struct {
+	Sources map[string]Source
+}
+
+// Source returns where the value of the dotted key came from. Keys are matched case-insensitively.
+func (m Metadata) Source(key string) (Source, bool) {
+	if source, ok := m.Sources[key]; ok {
+		return source, true
+	}
+
+	for sourceKey, source := range m.Sources {
+		if strings.EqualFold(sourceKey, key) {
+			return source, true
+		}
+	}
+
+	return Source{}, false
+}
+
+// IsSet reports whether the dotted key was set by a default, a config source or the environment.
+func (m Metadata) IsSet(key string) bool {
+	_, ok := m.Source(key)
+	return ok
+}
+
+// Metadata returns the metadata of the last successful load of this loader.
+// When the loader is shared between goroutines, "last" is whichever load finished last.
+func (loader *Loader[T]) Metadata() Metadata {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+
+	return Metadata{Sources: maps.Clone(loader.metadata.Sources)}
+}
+
+// IsSet reports whether the dotted key was set in the last successful load. See Metadata.
+func (loader *Loader[T]) IsSet(key string) bool {
+	return loader.Metadata().IsSet(key)
+}
+
+func (loader *Loader[T]) saveMetadata(state *loadState, config *T) {
+	metadata := Metadata{Sources: make(map[string]Source, len(state.sources))}
+	values := structToMap(reflect.ValueOf(config).Elem())
+	envValues := make(map[string]any)
+	for lowerKey, source := range state.sources {
+		key := lowerKey
+		if bound, ok := state.boundFields[lowerKey]; ok {
+			key = bound.key
+		}
+		metadata.Sources[key] = source
+
+		if source.Kind == SourceEnv {
+			if value, ok := lookupSettingFold(values, key); ok {
+				envValues[key] = value
+			}
+		}
+	}
+
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+
+	loader.metadata = metadata
+	loader.envValues = envValues
+}
+
+// Optional holds a config value together with whether it was set and where it came from,
+// so an unset key can be told apart from one set to the zero value:
+//
+//	PoolSize cong.Optional[int] `mapstructure:"pool_size"`
+//
+//	if size, ok := cfg.PoolSize.Get(); ok {
+//		pool.Resize(size)
+//	}
+type Optional[T any] struct {
+	Value  T
+	Set    bool
+	Source Source
+}
+
+// Get returns the value and whether it was set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set
+}
+
+// Or returns the value when it was set and fallback otherwise.
+func (o Optional[T]) Or(fallback T) T {
+	if o.Set {
+		return o.Value
+	}
+
+	return fallback
+}
+
+func (Optional[T]) optional() {}
+
+type optionalValue interface {
+	optional()
+}
+
+var optionalValueType = reflect.TypeOf((*optionalValue)(nil)).Elem()
+
+// optionalInner returns the type of the value held by an Optional type.
+func optionalInner(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Struct || !typ.Implements(optionalValueType) {
+		return nil, false
+	}
+
+	return typ.Field(0).Type, true
+}
+
+// optionalDecodeHook decodes a set key into an Optional by decoding the raw value into its Value field.
+func optionalDecodeHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	if _, ok := optionalInner(to); !ok || data == nil {
+		return data, nil
+	}
+
+	return map[string]any{"Value": data, "Set": true}, nil
+}
+
+// setOptionalSources fills the Source of every set Optional field of the decoded config.
+func setOptionalSources(state *loadState, val reflect.Value) {
+	_ = walkFields(val, "", func(key string, _ reflect.StructField, value reflect.Value) error {
+		if _, ok := optionalInner(value.Type()); !ok || !value.Field(1).Bool() {
+			return nil
+		}
+
+		if source, ok := state.sources[strings.ToLower(key)]; ok {
+			value.Field(2).Set(reflect.ValueOf(source))
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package cong
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type metadataTestPool struct {
+	Size    Optional[int]           `mapstructure:"size" min:"1"`
+	Timeout Optional[time.Duration] `mapstructure:"timeout"`
+	Tags    Optional[[]string]      `mapstructure:"tags"`
+}
+
+type metadataTestConfig struct {
+	Name  string           `mapstructure:"name" default:"hello"`
+	Port  int              `mapstructure:"port"`
+	Debug Optional[bool]   `mapstructure:"debug" default:"false"`
+	Level Optional[string] `mapstructure:"level" enum:"debug,info"`
+	Pool  metadataTestPool `mapstructure:"pool"`
+}
+
+func Test_Loader_Optional(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.yaml")
+	writeTestFile(t, path, "port: 0\npool:\n  size: 0\n  tags: [a, b]\n")
+
+	loader := NewLoader[metadataTestConfig](WithEnvMap(map[string]string{
+		"HELLO_POOL_TIMEOUT": "5s",
+		"HELLO_POOL_SIZE":    "",
+	}))
+
+	_, err := loader.Load("hello", YamlExt, dir)
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Equal("pool.size", validationErr.Errors[0].Key)
+
+	writeTestFile(t, path, "port: 0\npool:\n  size: 4\n  tags: [a, b]\n")
+
+	config, err := loader.Load("hello", YamlExt, dir)
+
+	as.Nil(err)
+	as.Equal(Optional[bool]{Value: false, Set: true, Source: Source{Kind: SourceDefault}}, config.Debug)
+	as.Equal(Optional[string]{}, config.Level)
+	as.Equal("info", config.Level.Or("info"))
+	as.Equal(Optional[int]{Value: 4, Set: true, Source: Source{Kind: SourceConfig, Name: path}}, config.Pool.Size)
+	as.Equal(Optional[time.Duration]{Value: 5 * time.Second, Set: true, Source: Source{Kind: SourceEnv, Name: "HELLO_POOL_TIMEOUT"}}, config.Pool.Timeout)
+
+	tags, ok := config.Pool.Tags.Get()
+	as.True(ok)
+	as.Equal([]string{"a", "b"}, tags)
+}
+
+func Test_Loader_Optional_decodeError(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[metadataTestConfig]().LoadFromMap("", map[string]any{"pool": map[string]any{"size": "many"}})
+
+	var decodeErr *DecodeError
+	as.True(errors.As(err, &decodeErr))
+	as.Equal("pool.size", decodeErr.Key)
+}
+
+func Test_Loader_Metadata(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[metadataTestConfig](WithEnvMap(map[string]string{"HELLO_PORT": "8080"}))
+
+	as.False(loader.IsSet("port"))
+
+	_, err := loader.LoadFromReader("hello", bytes.NewBufferString("port: 80\npool:\n  size: 2\n"), YamlExt)
+	as.Nil(err)
+
+	metadata := loader.Metadata()
+	as.Equal(map[string]Source{
+		"name":      {Kind: SourceDefault},
+		"port":      {Kind: SourceEnv, Name: "HELLO_PORT"},
+		"debug":     {Kind: SourceDefault},
+		"pool.size": {Kind: SourceConfig, Name: readerSourceName},
+	}, metadata.Sources)
+	as.True(loader.IsSet("Pool.Size"))
+	as.False(loader.IsSet("pool.timeout"))
+
+	_, err = loader.LoadFromMap("hello", map[string]any{"pool": map[string]any{"timeout": "1s"}})
+	as.Nil(err)
+
+	source, ok := loader.Metadata().Source("pool.timeout")
+	as.True(ok)
+	as.Equal("config map", source.String())
+	as.False(loader.IsSet("pool.size"))
+}
+
+func Test_Optional_schemaAndExample(t *testing.T) {
+	as := assert.New(t)
+
+	schema, err := GenerateJSONSchema[metadataTestConfig]()
+	as.Nil(err)
+	as.Equal("integer", schema.Properties["pool"].Properties["size"].Type)
+	as.Equal("boolean", schema.Properties["debug"].Type)
+
+	var example bytes.Buffer
+	as.Nil(WriteExample[metadataTestConfig](&example, YamlExt))
+	as.Contains(example.String(), "debug: false\n")
+	as.Contains(example.String(), "  size: 0\n")
+
+	as.Equal("int", EnvVars[metadataTestConfig]("hello")[4].Type.String())
+}
@@ -0,0 +1,167 @@
+package cong
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultVersionKey = "version"
+
+// Migration upgrades a config from version From to From+1 by editing the raw settings in place,
+// e.g. moving a renamed key. Settings are those of a single config source (a file, or a document of
+// a multi-document YAML file) before it is merged with the others, with nested maps and lower-cased keys:
+//
+//	cong.Migration{From: 1, Description: "port moved to server.port", Migrate: func(settings map[string]any) error {
+//		settings["server"] = map[string]any{"port": settings["port"]}
+//		delete(settings, "port")
+//		return nil
+//	}}
+//
+// Every source carries its own version; one without a version key is version 1. Migrations run one after another
+// until the current version is reached.
+type Migration struct {
+	From        int
+	Description string
+	Migrate     func(settings map[string]any) error
+}
+
+// MigrationError is returned when a config cannot be upgraded to the current version.
+type MigrationError struct {
+	From int
+	To   int
+	Err  error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("failed to migrate config from version %d to %d: %v", e.From, e.To, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// migrate upgrades the settings of a config source to the current version in place and reports whether they changed.
+func (loader *Loader[T]) migrate(settings map[string]any) (bool, error) {
+	if loader.options.currentVersion == 0 || len(settings) == 0 {
+		return false, nil
+	}
+
+	versionKey := strings.ToLower(loader.options.versionKey)
+
+	version, err := configVersion(settings, versionKey)
+	if err != nil {
+		return false, err
+	}
+
+	if version > loader.options.currentVersion {
+		return false, fmt.Errorf("config version %d is newer than the supported version %d", version, loader.options.currentVersion)
+	}
+
+	if version == loader.options.currentVersion {
+		return false, nil
+	}
+
+	for ; version < loader.options.currentVersion; version++ {
+		migration, ok := loader.options.migrations[version]
+		if !ok {
+			return false, &MigrationError{From: version, To: version + 1, Err: errors.New("no migration registered")}
+		}
+
+		if err := migration.Migrate(settings); err != nil {
+			return false, &MigrationError{From: version, To: version + 1, Err: err}
+		}
+
+		loader.options.logger.Warn("config migrated",
+			"from", version,
+			"to", version+1,
+			"description", migration.Description,
+		)
+	}
+
+	settings[versionKey] = loader.options.currentVersion
+
+	return true, nil
+}
+
+func configVersion(settings map[string]any, versionKey string) (int, error) {
+	value, ok := settings[versionKey]
+	if !ok {
+		return 1, nil
+	}
+
+	number, ok := toFloat(value)
+	if !ok || number != float64(int(number)) || number < 1 {
+		return 0, fmt.Errorf("invalid config version %v", value)
+	}
+
+	return int(number), nil
+}
+
+// writeUpgradedFile writes the upgraded documents of a config file back to it, merged into the existing content
+// the way Save does: YAML comments and the spelling of keys are kept, keys removed by migrations are dropped
+// and the other documents of a multi-document file are left as they are.
+func (loader *Loader[T]) writeUpgradedFile(
+	state *loadState,
+	path string,
+	existing []byte,
+	ext ConfigExtension,
+	documents []configDocument,
+	upgraded []bool,
+) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	values := make([]map[string]any, len(documents))
+	for i, document := range documents {
+		if upgraded[i] {
+			values[i] = upgradedValues(state, document)
+		}
+	}
+
+	var data []byte
+	switch ext {
+	case YamlExt, YmlExt:
+		data, err = saveYAMLDocuments(existing, values, true)
+	case JsonExt:
+		data, err = saveJSON(existing, values[0], true)
+	case TomlExt:
+		data, err = saveTOML(existing, values[0], true)
+	case DotenvExt, EnvExt:
+		data, err = saveDotenv(existing, values[0], true)
+	default:
+		err = fmt.Errorf("saving is not supported for %s", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write upgraded config %s: %w", path, err)
+	}
+
+	err = os.WriteFile(path, data, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	loader.options.logger.Warn("upgraded config written", "file", path, "version", loader.options.currentVersion)
+
+	return nil
+}
+
+// upgradedValues returns the settings of a migrated document with the keys the file holds but the settings
+// do not: its profile key and the nulls that remove inherited values.
+func upgradedValues(state *loadState, document configDocument) map[string]any {
+	values := copyMap(document.settings)
+	if document.profile != nil {
+		values[state.profileKey] = document.profile
+	}
+
+	for key, directive := range document.directives {
+		if directive == mergeRemove {
+			setSetting(values, key, nil)
+		}
+	}
+
+	return values
+}
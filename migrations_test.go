@@ -0,0 +1,259 @@
+package cong
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type migrationTestServer struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type migrationTestConfig struct {
+	Version int                 `mapstructure:"version"`
+	Name    string              `mapstructure:"name"`
+	Server  migrationTestServer `mapstructure:"server"`
+}
+
+var migrationTestMigrations = []Migration{
+	{
+		From:        1,
+		Description: "port moved to server.port",
+		Migrate: func(settings map[string]any) error {
+			settings["server"] = map[string]any{"port": settings["port"]}
+			delete(settings, "port")
+			return nil
+		},
+	},
+	{
+		From:        2,
+		Description: "title renamed to name",
+		Migrate: func(settings map[string]any) error {
+			settings["name"] = settings["title"]
+			delete(settings, "title")
+			return nil
+		},
+	},
+}
+
+func Test_Loader_migrations(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[migrationTestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	config, err := loader.LoadFromReader("", bytes.NewBufferString("title: hello\nport: 80\n"), YamlExt)
+
+	as.Nil(err)
+	as.Equal(3, config.Version)
+	as.Equal("hello", config.Name)
+	as.Equal(80, config.Server.Port)
+	as.Contains(logs.String(), "from=1 to=2")
+	as.Contains(logs.String(), `description="title renamed to name"`)
+}
+
+func Test_Loader_migrations_currentVersion(t *testing.T) {
+	as := assert.New(t)
+
+	var logs bytes.Buffer
+	loader := NewLoader[migrationTestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	config, err := loader.LoadFromMap("", map[string]any{"version": 3, "name": "hello"})
+
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+	as.Empty(logs.String())
+}
+
+func Test_Loader_migrations_errors(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[migrationTestConfig](WithMigrations(3, migrationTestMigrations[0]))
+
+	_, err := loader.LoadFromMap("", map[string]any{"version": 4})
+	as.ErrorContains(err, "newer than the supported version 3")
+
+	_, err = loader.LoadFromMap("", map[string]any{"version": 2})
+	var migrationErr *MigrationError
+	as.True(errors.As(err, &migrationErr))
+	as.Equal(2, migrationErr.From)
+	as.Equal(3, migrationErr.To)
+
+	_, err = loader.LoadFromMap("", map[string]any{"version": "one"})
+	as.ErrorContains(err, "invalid config version")
+}
+
+func Test_Loader_migrations_versionKey(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Schema int    `mapstructure:"schema"`
+		Name   string `mapstructure:"name"`
+	}
+
+	loader := NewLoader[TestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithVersionKey("schema"),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+
+	config, err := loader.LoadFromMap("", map[string]any{"schema": 2, "title": "hello"})
+
+	as.Nil(err)
+	as.Equal(3, config.Schema)
+	as.Equal("hello", config.Name)
+}
+
+func Test_Loader_migrations_writeUpgradedFile(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, `# the name
+title: hello
+port: 80 # public port
+`)
+
+	loader := NewLoader[migrationTestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithWriteUpgradedFile(),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+
+	config, err := loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal(`name: hello
+server:
+  port: 80
+version: 3
+`, string(data))
+
+	config, err = loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+	as.Equal(80, config.Server.Port)
+}
+
+func Test_Check_migrations(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Name   string              `mapstructure:"name"`
+		Server migrationTestServer `mapstructure:"server"`
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, "title: hello\nport: 80\n")
+
+	loader := NewLoader[TestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithWriteUpgradedFile(),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+
+	as.Nil(loader.Check(path))
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal("title: hello\nport: 80\n", string(data))
+}
+
+func Test_Loader_migrations_perFile(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.yaml"), "version: 1\nport: 5\n")
+	writeTestFile(t, filepath.Join(dir, "b.yaml"), "version: 3\nname: hello\n")
+
+	loader := NewLoader[migrationTestConfig](
+		WithMigrations(3, migrationTestMigrations...),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+
+	config, err := loader.LoadFromDir("hello", dir, YamlExt)
+
+	as.Nil(err)
+	as.Equal(3, config.Version)
+	as.Equal("hello", config.Name)
+	as.Equal(5, config.Server.Port)
+}
+
+func Test_Loader_migrations_writeUpgradedFile_json(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Version int               `mapstructure:"version"`
+		Name    string            `mapstructure:"name"`
+		Labels  map[string]string `mapstructure:"labels"`
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.json")
+	writeTestFile(t, path, `{"Version": 2, "Title": "hello", "Labels": {"TeamName": "core"}}`)
+
+	loader := NewLoader[TestConfig](
+		WithMigrations(3, migrationTestMigrations[1]),
+		WithWriteUpgradedFile(),
+		WithPreserveKeyCase(KeyCaseMaps),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	config, err := loader.Load("hello", JsonExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+	as.Equal(map[string]string{"TeamName": "core"}, config.Labels)
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.JSONEq(`{"Version": 3, "Labels": {"TeamName": "core"}, "name": "hello"}`, string(data))
+}
+
+func Test_Loader_migrations_writeUpgradedFile_multiDocument(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, `version: 2
+title: hello
+---
+profile: prod
+version: 2
+title: world # production name
+port: ~
+`)
+
+	loader := NewLoader[migrationTestConfig](
+		WithMigrations(3, migrationTestMigrations[1]),
+		WithProfile("prod"),
+		WithWriteUpgradedFile(),
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+
+	config, err := loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal("world", config.Name)
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal(`version: 3
+name: hello
+---
+profile: prod
+version: 3
+port: ~
+name: world
+`, string(data))
+}
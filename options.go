@@ -0,0 +1,237 @@
+package cong
+
+import (
+	"flag"
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+// Option configures a Loader created by NewLoader.
+type Option func(*options)
+
+type options struct {
+	optionalFile        bool
+	searchPaths         []string
+	standardSearchPaths bool
+	mergeAllFound       bool
+	configFile          string
+	configEnv           bool
+	configFlagSet       *flag.FlagSet
+	configFlagName      string
+	logger              *slog.Logger
+	currentVersion      int
+	versionKey          string
+	migrations          map[int]Migration
+	writeUpgradedFile   bool
+	dotenv              bool
+	dotenvDir           string
+	dotenvProfile       string
+	lookupEnv           envLookup
+	rules               []Rule
+	base                any
+	strictTypes         bool
+	keyCase             KeyCase
+	profile             string
+	profileKey          string
+	checkExt            *ConfigExtension
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		logger:     slog.Default(),
+		versionKey: defaultVersionKey,
+		profileKey: defaultProfileKey,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithOptionalFile makes Load treat a missing config file as an empty file layer,
+// so defaults and environment variables are still applied.
+// Files that exist but cannot be read or parsed still fail the load.
+func WithOptionalFile() Option {
+	return func(o *options) {
+		o.optionalFile = true
+	}
+}
+
+// WithSearchPaths replaces the default search paths used by Load (".", "./config", "./static").
+// Paths are ordered from the highest to the lowest precedence. Paths passed to Load directly still win.
+func WithSearchPaths(paths ...string) Option {
+	return func(o *options) {
+		o.searchPaths = paths
+	}
+}
+
+// WithStandardSearchPaths makes Load search the locations returned by StandardSearchPaths.
+func WithStandardSearchPaths() Option {
+	return func(o *options) {
+		o.standardSearchPaths = true
+	}
+}
+
+// WithMergeAllFound makes Load merge every config file found in the search paths instead of stopping at the first one.
+// Files are merged from the lowest to the highest precedence (system -> user -> local), so local files win.
+func WithMergeAllFound() Option {
+	return func(o *options) {
+		o.mergeAllFound = true
+	}
+}
+
+// WithConfigFile makes Load read the given file instead of searching for <projectName>.<ext>.
+// The format is inferred from the file extension.
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithConfigEnv makes Load read the file named by the <PROJECT>_CONFIG environment variable when it is set,
+// unless the config flag or WithConfigFile sets one. Loading fails when a field is bound to the same variable.
+func WithConfigEnv() Option {
+	return func(o *options) {
+		o.configEnv = true
+	}
+}
+
+// WithConfigFlag makes Load read the file passed in the named flag (e.g. "config" for --config) when it is set.
+// The flag must be defined on flagSet and parsed before Load is called.
+func WithConfigFlag(flagSet *flag.FlagSet, name string) Option {
+	return func(o *options) {
+		o.configFlagSet = flagSet
+		o.configFlagName = name
+	}
+}
+
+// WithLogger sets the logger the loader reports to: warnings about migrated configs and deprecated keys,
+// and at debug level the searched paths, the merged files in order and the env vars overriding keys,
+// with the values of secret fields redacted. slog.Default() is used when the option is not given.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMigrations declares the current config version and the migrations that upgrade older configs to it.
+// See Migration for how the version of a config is determined.
+func WithMigrations(currentVersion int, migrations ...Migration) Option {
+	return func(o *options) {
+		o.currentVersion = currentVersion
+		o.migrations = make(map[int]Migration, len(migrations))
+		for _, migration := range migrations {
+			o.migrations[migration.From] = migration
+		}
+	}
+}
+
+// WithVersionKey changes the key holding the config version, "version" by default.
+func WithVersionKey(key string) Option {
+	return func(o *options) {
+		o.versionKey = key
+	}
+}
+
+// WithWriteUpgradedFile makes the loader write a migrated config back to its file, so the upgrade is done once.
+// It only applies when the config came from a single file on disk; otherwise a warning is logged instead.
+func WithWriteUpgradedFile() Option {
+	return func(o *options) {
+		o.writeUpgradedFile = true
+	}
+}
+
+// WithDotenv makes the loader read .env, .env.local and .env.<profile> from dir (in increasing precedence)
+// and use their variables, e.g. HELLO_PORT=8080, like real environment variables, which still take precedence.
+// The process environment is not modified. Missing files are skipped; an empty profile reads only the first two.
+// ${VAR} references in the files are expanded from the loader's environment, then from the file's earlier variables.
+func WithDotenv(dir string, profile string) Option {
+	return func(o *options) {
+		o.dotenv = true
+		o.dotenvDir = dir
+		o.dotenvProfile = profile
+	}
+}
+
+// WithEnviron makes the loader use the given environment instead of the process one, in the os.Environ
+// "NAME=value" form. Every Load* method, the <PROJECT>_CONFIG variable of WithConfigEnv and the standard search paths
+// use it, so tests setting it stay hermetic and can run in parallel.
+func WithEnviron(environ []string) Option {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		env[name] = value
+	}
+
+	return WithEnvMap(env)
+}
+
+// WithEnvMap makes the loader use the given variables instead of the process environment. See WithEnviron.
+func WithEnvMap(env map[string]string) Option {
+	env = maps.Clone(env)
+
+	return WithEnvLookup(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+}
+
+// WithEnvLookup makes the loader look environment variables up with lookupEnv, which has the signature of
+// os.LookupEnv, instead of reading the process environment. See WithEnviron.
+func WithEnvLookup(lookupEnv func(name string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookupEnv = lookupEnv
+	}
+}
+
+// WithRules adds cross-field rules, e.g. cong.ExactlyOneOf("db.url", "db.host"), checked on every load.
+func WithRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rules...)
+	}
+}
+
+// WithStrictTypes disables the weak coercion of values: "abc" or 1.5 for an int, 300 for an int8, "yes" for a bool,
+// 123 for a string or 30 for a duration fail with a DecodeError instead of being converted, truncated or zeroed.
+// Strings, e.g. from env vars, are still parsed into numbers, bools and durations.
+func WithStrictTypes() Option {
+	return func(o *options) {
+		o.strictTypes = true
+	}
+}
+
+// WithPreserveKeyCase keeps the case keys are written with in YAML, JSON and TOML sources for the keys selected
+// by keyCase, e.g. cong.KeyCaseMaps for a map of HTTP headers, instead of lower-casing them.
+func WithPreserveKeyCase(keyCase KeyCase) Option {
+	return func(o *options) {
+		o.keyCase = keyCase
+	}
+}
+
+// WithProfile selects the documents of multi-document YAML files to merge: documents with a profile key, e.g.
+// "profile: prod" or "profile: [staging, prod]", only apply when it names this profile. Documents without it
+// always apply, in file order.
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithProfileKey sets the key that selects the documents of multi-document YAML files ("profile" by default).
+// Loading such a file fails when the key is also a config field.
+func WithProfileKey(key string) Option {
+	return func(o *options) {
+		o.profileKey = key
+	}
+}
+
+// WithCheckExtension makes Check read only the files with this extension from directories, like LoadFromDir does,
+// so e.g. a schema.json next to YAML configs is not checked as config. Files passed explicitly are always checked.
+// Without it, directories are filtered by the extension of the first file passed, or of the first config file found.
+func WithCheckExtension(ext ConfigExtension) Option {
+	return func(o *options) {
+		o.checkExt = &ext
+	}
+}
@@ -0,0 +1,20 @@
+package cong
+
+import "reflect"
+
+// Redact returns the values of config as nested maps keyed the way the loader reads them,
+// with the values of secret fields (see the secret tag) replaced by "[REDACTED]".
+// It is meant for logging or snapshotting the effective config.
+func Redact[T any](config *T) map[string]any {
+	values := structToMap(reflect.ValueOf(config).Elem())
+
+	_ = walkFields(reflect.ValueOf(config).Elem(), "", func(key string, field reflect.StructField, _ reflect.Value) error {
+		if _, ok := lookupSetting(values, key); ok && isSecret(key, field) {
+			setSetting(values, key, redactedValue)
+		}
+
+		return nil
+	})
+
+	return values
+}
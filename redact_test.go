@@ -0,0 +1,40 @@
+package cong
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Redact(t *testing.T) {
+	as := assert.New(t)
+
+	type Database struct {
+		Host     string `mapstructure:"host"`
+		Password string `mapstructure:"password"`
+		DSN      string `mapstructure:"dsn" secret:"true"`
+	}
+
+	type TestConfig struct {
+		Name     string   `mapstructure:"name"`
+		APIToken string   `mapstructure:"apiToken" secret:"false"`
+		Database Database `mapstructure:"database"`
+	}
+
+	config := &TestConfig{
+		Name:     "hello",
+		APIToken: "public",
+		Database: Database{Host: "db", Password: "hunter2", DSN: "postgres://user:pass@db"},
+	}
+
+	as.Equal(map[string]any{
+		"name":     "hello",
+		"apiToken": "public",
+		"database": map[string]any{
+			"host":     "db",
+			"password": redactedValue,
+			"dsn":      redactedValue,
+		},
+	}, Redact(config))
+	as.Equal("hunter2", config.Database.Password)
+}
@@ -0,0 +1,287 @@
+package cong
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	requiredIfTag        = "required_if"
+	requiredWithTag      = "required_with"
+	mutuallyExclusiveTag = "mutually_exclusive"
+	exactlyOneOfTag      = "exactly_one_of"
+)
+
+// comparisonTags are the field comparison tags, e.g. lte_field:"pool.max", with their operators.
+var comparisonTags = []struct {
+	tag string
+	op  string
+}{
+	{tag: "eq_field", op: "=="},
+	{tag: "ne_field", op: "!="},
+	{tag: "lt_field", op: "<"},
+	{tag: "lte_field", op: "<="},
+	{tag: "gt_field", op: ">"},
+	{tag: "gte_field", op: ">="},
+}
+
+var comparisonNames = map[string]string{
+	"==": "equal to",
+	"!=": "different from",
+	"<":  "less than",
+	"<=": "less than or equal to",
+	">":  "greater than",
+	">=": "greater than or equal to",
+}
+
+// Rule is a requirement spanning several config keys, checked after the validation tags.
+// Rules are declared with the required_if, required_with, mutually_exclusive, exactly_one_of
+// and *_field tags, or built with RequiredIf, RequiredWith, MutuallyExclusive, ExactlyOneOf and Compare
+// and passed to WithRules. Keys are dotted and start at the config root.
+type Rule struct {
+	name  string
+	keys  []string
+	check func(fields ruleFields) (key string, message string)
+}
+
+// ruleFields gives rules access to the decoded fields by lower-cased dotted key.
+type ruleFields struct {
+	values map[string]reflect.Value
+	isSet  func(key string) bool
+}
+
+func (fields ruleFields) value(key string) (reflect.Value, bool) {
+	value, ok := fields.values[strings.ToLower(key)]
+	if !ok || !fields.isSet(key) {
+		return reflect.Value{}, false
+	}
+
+	return indirect(value)
+}
+
+func (fields ruleFields) setKeys(keys []string) []string {
+	var set []string
+	for _, key := range keys {
+		if _, ok := fields.value(key); ok {
+			set = append(set, key)
+		}
+	}
+
+	return set
+}
+
+// RequiredIf requires key when conditionKey is set to value, e.g. RequiredIf("tls.cert_file", "tls.enabled", true).
+func RequiredIf(key string, conditionKey string, value any) Rule {
+	expected := fmt.Sprint(value)
+
+	return Rule{
+		name: requiredIfTag,
+		keys: []string{key, conditionKey},
+		check: func(fields ruleFields) (string, string) {
+			condition, ok := fields.value(conditionKey)
+			if !ok || fmt.Sprint(condition.Interface()) != expected || fields.isSet(key) {
+				return "", ""
+			}
+
+			return key, fmt.Sprintf("is required when %s is %s", conditionKey, expected)
+		},
+	}
+}
+
+// RequiredWith requires key when any of the other keys is set.
+func RequiredWith(key string, others ...string) Rule {
+	return Rule{
+		name: requiredWithTag,
+		keys: append([]string{key}, others...),
+		check: func(fields ruleFields) (string, string) {
+			set := fields.setKeys(others)
+			if len(set) == 0 || fields.isSet(key) {
+				return "", ""
+			}
+
+			return key, fmt.Sprintf("is required when %s is set", strings.Join(set, ", "))
+		},
+	}
+}
+
+// MutuallyExclusive allows at most one of the keys to be set.
+func MutuallyExclusive(keys ...string) Rule {
+	return Rule{
+		name: mutuallyExclusiveTag,
+		keys: keys,
+		check: func(fields ruleFields) (string, string) {
+			set := fields.setKeys(keys)
+			if len(set) <= 1 {
+				return "", ""
+			}
+
+			return set[0], fmt.Sprintf("cannot be set together with %s", strings.Join(set[1:], ", "))
+		},
+	}
+}
+
+// ExactlyOneOf requires exactly one of the keys to be set, e.g. ExactlyOneOf("db.url", "db.host").
+func ExactlyOneOf(keys ...string) Rule {
+	return Rule{
+		name: exactlyOneOfTag,
+		keys: keys,
+		check: func(fields ruleFields) (string, string) {
+			set := fields.setKeys(keys)
+			switch len(set) {
+			case 1:
+				return "", ""
+			case 0:
+				return keys[0], fmt.Sprintf("or one of %s must be set", strings.Join(keys[1:], ", "))
+			default:
+				return set[0], fmt.Sprintf("cannot be set together with %s, exactly one of them is allowed", strings.Join(set[1:], ", "))
+			}
+		},
+	}
+}
+
+// Compare requires the value of key to relate to the value of otherKey by op, one of ==, !=, <, <=, > and >=,
+// e.g. Compare("pool.min", "<=", "pool.max"). Numbers, durations and strings can be ordered.
+// The rule is skipped while either key is unset.
+func Compare(key string, op string, otherKey string) Rule {
+	return Rule{
+		name: "compare",
+		keys: []string{key, otherKey},
+		check: func(fields ruleFields) (string, string) {
+			value, ok := fields.value(key)
+			other, otherOK := fields.value(otherKey)
+			if !ok || !otherOK {
+				return "", ""
+			}
+
+			name, known := comparisonNames[op]
+			if !known {
+				return key, fmt.Sprintf("has an unknown comparison operator %q", op)
+			}
+
+			holds, comparable := compareValues(value, op, other)
+			if !comparable {
+				return key, fmt.Sprintf("cannot be compared with %s", otherKey)
+			}
+			if holds {
+				return "", ""
+			}
+
+			return key, fmt.Sprintf("must be %s %s (%v), got %v", name, otherKey, other.Interface(), value.Interface())
+		},
+	}
+}
+
+func compareValues(value reflect.Value, op string, other reflect.Value) (bool, bool) {
+	if op == "==" || op == "!=" {
+		equal := reflect.DeepEqual(value.Interface(), other.Interface())
+		return equal == (op == "=="), true
+	}
+
+	var sign int
+	number, isNumber := numberOf(value)
+	otherNumber, otherIsNumber := numberOf(other)
+	switch {
+	case isNumber && otherIsNumber:
+		sign = compareNumbers(number, otherNumber)
+	case value.Kind() == reflect.String && other.Kind() == reflect.String:
+		sign = strings.Compare(value.String(), other.String())
+	default:
+		return false, false
+	}
+
+	switch op {
+	case "<":
+		return sign < 0, true
+	case "<=":
+		return sign <= 0, true
+	case ">":
+		return sign > 0, true
+	default:
+		return sign >= 0, true
+	}
+}
+
+// tagRules builds the rules declared with tags on the field bound to key.
+func tagRules(key string, field reflect.StructField) []Rule {
+	var rules []Rule
+
+	if tag := field.Tag.Get(requiredIfTag); tag != "" {
+		conditionKey, value, _ := strings.Cut(tag, "=")
+		rules = append(rules, RequiredIf(key, strings.TrimSpace(conditionKey), strings.TrimSpace(value)))
+	}
+
+	if others := tagKeys(field, requiredWithTag); others != nil {
+		rules = append(rules, RequiredWith(key, others...))
+	}
+
+	if others := tagKeys(field, mutuallyExclusiveTag); others != nil {
+		rules = append(rules, MutuallyExclusive(append([]string{key}, others...)...))
+	}
+
+	if others := tagKeys(field, exactlyOneOfTag); others != nil {
+		rules = append(rules, ExactlyOneOf(append([]string{key}, others...)...))
+	}
+
+	for _, comparison := range comparisonTags {
+		if otherKey := field.Tag.Get(comparison.tag); otherKey != "" {
+			rules = append(rules, Compare(key, comparison.op, otherKey))
+		}
+	}
+
+	return rules
+}
+
+func tagKeys(field reflect.StructField, tag string) []string {
+	value := field.Tag.Get(tag)
+	if value == "" {
+		return nil
+	}
+
+	keys := strings.Split(value, ",")
+	for i, key := range keys {
+		keys[i] = strings.TrimSpace(key)
+	}
+
+	return keys
+}
+
+// checkRules runs the rules declared with tags and WithRules on the decoded config.
+func (loader *Loader[T]) checkRules(state *loadState, config *T) []*FieldError {
+	fields := ruleFields{
+		values: make(map[string]reflect.Value),
+		isSet:  state.viper.IsSet,
+	}
+
+	var rules []Rule
+	_ = walkFields(reflect.ValueOf(config).Elem(), "", func(key string, field reflect.StructField, value reflect.Value) error {
+		fields.values[strings.ToLower(key)] = value
+		rules = append(rules, tagRules(key, field)...)
+		return nil
+	})
+	rules = append(rules, loader.options.rules...)
+
+	var fieldErrs []*FieldError
+	for _, rule := range rules {
+		key, message := rule.check(fields)
+
+		for _, ruleKey := range rule.keys {
+			if _, ok := fields.values[strings.ToLower(ruleKey)]; !ok {
+				key, message = ruleKey, "is referenced by a "+rule.name+" rule but is not a config key"
+				break
+			}
+		}
+
+		if message != "" {
+			fieldErrs = append(fieldErrs, &FieldError{
+				Key:     key,
+				Keys:    rule.keys,
+				EnvVar:  state.boundFields[strings.ToLower(key)].envVar,
+				Rule:    rule.name,
+				Message: message,
+			})
+		}
+	}
+
+	return fieldErrs
+}
@@ -0,0 +1,124 @@
+package cong
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rulesTestTLS struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file" required_if:"tls.enabled=true"`
+	KeyFile  string `mapstructure:"key_file" required_if:"tls.enabled=true"`
+}
+
+type rulesTestDB struct {
+	URL      string `mapstructure:"url" exactly_one_of:"db.host"`
+	Host     string `mapstructure:"host"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password" required_with:"db.user"`
+}
+
+type rulesTestPool struct {
+	Min     int           `mapstructure:"min" lte_field:"pool.max"`
+	Max     int           `mapstructure:"max"`
+	Idle    time.Duration `mapstructure:"idle" lt_field:"pool.timeout"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type rulesTestConfig struct {
+	TLS  rulesTestTLS  `mapstructure:"tls"`
+	DB   rulesTestDB   `mapstructure:"db"`
+	Pool rulesTestPool `mapstructure:"pool"`
+}
+
+func rulesTestErrors(t *testing.T, err error) map[string]*FieldError {
+	t.Helper()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	fieldErrs := make(map[string]*FieldError)
+	for _, fieldErr := range validationErr.Errors {
+		fieldErrs[fieldErr.Rule+" "+fieldErr.Key] = fieldErr
+	}
+
+	return fieldErrs
+}
+
+func Test_Loader_rules_valid(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[rulesTestConfig]().LoadFromMap("", map[string]any{
+		"tls":  map[string]any{"enabled": true, "cert_file": "cert.pem", "key_file": "key.pem"},
+		"db":   map[string]any{"host": "localhost", "user": "app", "password": "secret"},
+		"pool": map[string]any{"min": 1, "max": 1, "idle": "1s", "timeout": "1m"},
+	})
+
+	as.Nil(err)
+}
+
+func Test_Loader_rules_tags(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[rulesTestConfig](WithEnvMap(nil)).LoadFromMap("hello", map[string]any{
+		"tls":  map[string]any{"enabled": true, "cert_file": "cert.pem"},
+		"db":   map[string]any{"url": "postgres://db", "host": "localhost", "user": "app"},
+		"pool": map[string]any{"min": 10, "max": 5, "idle": "2m", "timeout": "1m"},
+	})
+
+	fieldErrs := rulesTestErrors(t, err)
+	as.Len(fieldErrs, 5)
+
+	keyFile := fieldErrs["required_if tls.key_file"]
+	as.Equal("is required when tls.enabled is true", keyFile.Message)
+	as.Equal([]string{"tls.key_file", "tls.enabled"}, keyFile.Keys)
+	as.Equal("HELLO_TLS_KEY_FILE", keyFile.EnvVar)
+
+	as.Equal("cannot be set together with db.host, exactly one of them is allowed", fieldErrs["exactly_one_of db.url"].Message)
+	as.Equal([]string{"db.url", "db.host"}, fieldErrs["exactly_one_of db.url"].Keys)
+	as.Equal("is required when db.user is set", fieldErrs["required_with db.password"].Message)
+	as.Equal("must be less than or equal to pool.max (5), got 10", fieldErrs["compare pool.min"].Message)
+	as.Equal("must be less than pool.timeout (1m0s), got 2m0s", fieldErrs["compare pool.idle"].Message)
+}
+
+func Test_Loader_rules_exactlyOneOf_none(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[rulesTestConfig]().LoadFromMap("", map[string]any{"tls": map[string]any{"enabled": false}})
+
+	fieldErrs := rulesTestErrors(t, err)
+	as.Len(fieldErrs, 1)
+	as.Equal("or one of db.host must be set", fieldErrs["exactly_one_of db.url"].Message)
+}
+
+func Test_Loader_rules_builder(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Cache struct {
+			Redis  string `mapstructure:"redis"`
+			Memory bool   `mapstructure:"memory"`
+			Size   int    `mapstructure:"size"`
+		} `mapstructure:"cache"`
+	}
+
+	loader := NewLoader[TestConfig](WithRules(
+		MutuallyExclusive("cache.redis", "cache.memory"),
+		RequiredIf("cache.size", "cache.memory", true),
+		Compare("cache.size", ">", "cache.unknown"),
+	))
+
+	_, err := loader.LoadFromMap("", map[string]any{"cache": map[string]any{"redis": "localhost:6379", "memory": true}})
+
+	fieldErrs := rulesTestErrors(t, err)
+	as.Len(fieldErrs, 3)
+	as.Equal("cannot be set together with cache.memory", fieldErrs["mutually_exclusive cache.redis"].Message)
+	as.Equal([]string{"cache.redis", "cache.memory"}, fieldErrs["mutually_exclusive cache.redis"].Keys)
+	as.Equal("is required when cache.memory is true", fieldErrs["required_if cache.size"].Message)
+	as.Equal("is referenced by a compare rule but is not a config key", fieldErrs["compare cache.unknown"].Message)
+}
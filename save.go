@@ -0,0 +1,448 @@
+package cong
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/subosito/gotenv"
+	"go.yaml.in/yaml/v3"
+)
+
+const defaultFilePermissions fs.FileMode = 0o644
+
+// Save writes config to the file at path in the given format (YAML, JSON, TOML or dotenv).
+// When the file already exists, its keys that T does not know about are preserved, and for YAML
+// comments and key order are kept as well, so a loaded config can be modified and written back.
+// Values the last load of this loader took from environment variables are not written unless they were changed,
+// so secrets passed through the environment do not end up in the file; the file keeps its own value for them.
+func (loader *Loader[T]) Save(config *T, path string, ext ConfigExtension) error {
+	values := structToMap(reflect.ValueOf(config).Elem())
+	loader.dropEnvValues(values)
+
+	perm := defaultFilePermissions
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		existing = nil
+	case err != nil:
+		return err
+	default:
+		if info, err := os.Stat(path); err == nil {
+			perm = info.Mode().Perm()
+		}
+	}
+
+	var data []byte
+	switch ext {
+	case YamlExt, YmlExt:
+		data, err = saveYAML(existing, values, false)
+	case JsonExt:
+		data, err = saveJSON(existing, values, false)
+	case TomlExt:
+		data, err = saveTOML(existing, values, false)
+	case DotenvExt, EnvExt:
+		data, err = saveDotenv(existing, values, false)
+	default:
+		return fmt.Errorf("saving is not supported for %s", ext)
+	}
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.File = path
+		}
+		return err
+	}
+
+	return os.WriteFile(path, data, perm)
+}
+
+// dropEnvValues removes from values the keys set by environment variables in the last load that still hold
+// the loaded value.
+func (loader *Loader[T]) dropEnvValues(values map[string]any) {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+
+	for key, envValue := range loader.envValues {
+		if value, ok := lookupSettingFold(values, key); ok && reflect.DeepEqual(value, envValue) {
+			deleteSettingFold(values, key)
+		}
+	}
+}
+
+// lookupSettingFold finds a dotted key in nested settings, matching every part of it case-insensitively.
+func lookupSettingFold(settings map[string]any, key string) (any, bool) {
+	name, rest, nested := strings.Cut(key, ".")
+
+	_, value, ok := findFold(settings, name)
+	if !ok || !nested {
+		return value, ok
+	}
+
+	child, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return lookupSettingFold(child, rest)
+}
+
+// deleteSettingFold removes a dotted key from nested settings, matching every part of it case-insensitively.
+func deleteSettingFold(settings map[string]any, key string) {
+	name, rest, nested := strings.Cut(key, ".")
+
+	existingKey, value, ok := findFold(settings, name)
+	if !ok {
+		return
+	}
+	if !nested {
+		delete(settings, existingKey)
+		return
+	}
+
+	if child, ok := value.(map[string]any); ok {
+		deleteSettingFold(child, rest)
+		if len(child) == 0 {
+			delete(settings, existingKey)
+		}
+	}
+}
+
+// structToMap converts a config struct into nested maps keyed the way the loader reads them.
+func structToMap(val reflect.Value) map[string]any {
+	res := make(map[string]any)
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		value := plainValue(val.Field(i))
+		if nested, ok := value.(map[string]any); ok && isSquashed(field) {
+			for key, nestedValue := range nested {
+				res[key] = nestedValue
+			}
+			continue
+		}
+
+		res[name] = value
+	}
+
+	return res
+}
+
+// plainValue converts a config value into the plain maps, slices and scalars the encoders understand.
+func plainValue(val reflect.Value) any {
+	if _, ok := optionalInner(val.Type()); ok {
+		if !val.Field(1).Bool() {
+			return nil
+		}
+		return plainValue(val.Field(0))
+	}
+
+	if val.Type() == durationType {
+		return val.Interface().(fmt.Stringer).String()
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return plainValue(val.Elem())
+	case reflect.Struct:
+		return structToMap(val)
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return []any{}
+		}
+		items := make([]any, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			items = append(items, plainValue(val.Index(i)))
+		}
+		return items
+	case reflect.Map:
+		res := make(map[string]any, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			res[fmt.Sprint(iter.Key().Interface())] = plainValue(iter.Value())
+		}
+		return res
+	default:
+		return val.Interface()
+	}
+}
+
+// mergeFold overlays values onto existing, matching keys case-insensitively like the loader does,
+// and keeps the original spelling of existing keys. Keys only present in existing are kept, or dropped when prune is set.
+func mergeFold(existing map[string]any, values map[string]any, prune bool) map[string]any {
+	res := make(map[string]any, len(existing)+len(values))
+	if !prune {
+		for key, value := range existing {
+			res[key] = value
+		}
+	}
+
+	for key, value := range values {
+		existingKey, existingValue, found := findFold(existing, key)
+		if !found {
+			res[key] = value
+			continue
+		}
+
+		nested, isMap := value.(map[string]any)
+		existingNested, existingIsMap := existingValue.(map[string]any)
+		if isMap && existingIsMap {
+			value = mergeFold(existingNested, nested, prune)
+		}
+		res[existingKey] = value
+	}
+
+	return res
+}
+
+func findFold(m map[string]any, key string) (string, any, bool) {
+	if value, ok := m[key]; ok {
+		return key, value, true
+	}
+
+	for existingKey, value := range m {
+		if strings.EqualFold(existingKey, key) {
+			return existingKey, value, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func saveJSON(existing []byte, values map[string]any, prune bool) ([]byte, error) {
+	if len(existing) > 0 {
+		var current map[string]any
+		if err := json.Unmarshal(existing, &current); err != nil {
+			return nil, newParseError("", existing, err)
+		}
+		values = mergeFold(current, values, prune)
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func saveTOML(existing []byte, values map[string]any, prune bool) ([]byte, error) {
+	if len(existing) > 0 {
+		var current map[string]any
+		if err := toml.Unmarshal(existing, &current); err != nil {
+			return nil, newParseError("", existing, err)
+		}
+		values = mergeFold(current, values, prune)
+	}
+
+	return toml.Marshal(values)
+}
+
+func saveDotenv(existing []byte, values map[string]any, prune bool) ([]byte, error) {
+	flat := make(map[string]any)
+	flattenMap(flat, values, "")
+
+	if len(existing) > 0 {
+		current, err := gotenv.StrictParse(bytes.NewReader(existing))
+		if err != nil {
+			return nil, newParseError("", existing, err)
+		}
+
+		currentValues := make(map[string]any, len(current))
+		for key, value := range current {
+			currentValues[key] = value
+		}
+		flat = mergeFold(currentValues, flat, prune)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		switch value := flat[key].(type) {
+		case nil:
+			continue
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			_, _ = fmt.Fprintf(&sb, "%s=%s\n", key, dotenvValue(strings.Join(items, ",")))
+		default:
+			_, _ = fmt.Fprintf(&sb, "%s=%s\n", key, dotenvValue(fmt.Sprint(value)))
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+func flattenMap(flat map[string]any, m map[string]any, prefix string) {
+	for key, value := range m {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenMap(flat, nested, joinKey(prefix, key))
+			continue
+		}
+		flat[joinKey(prefix, key)] = value
+	}
+}
+
+// saveYAML writes values over a single-document YAML file. The documents of a multi-document file are layers
+// merged into one config, which cannot be split back, so such files are rejected.
+func saveYAML(existing []byte, values map[string]any, prune bool) ([]byte, error) {
+	docs, err := decodeYAMLDocuments(existing)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) > 1 {
+		return nil, errors.New("saving multi-document YAML files is not supported")
+	}
+
+	return saveYAMLDocuments(existing, []map[string]any{values}, prune)
+}
+
+// saveYAMLDocuments writes values[i] over the i-th non-empty document of a YAML file. Documents without values
+// are written back unchanged.
+func saveYAMLDocuments(existing []byte, values []map[string]any, prune bool) ([]byte, error) {
+	docs, err := decodeYAMLDocuments(existing)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		docs = []*yaml.Node{{Kind: yaml.DocumentNode}}
+	}
+
+	for i, doc := range docs {
+		if i >= len(values) || values[i] == nil {
+			continue
+		}
+
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+		}
+		if err := mergeYAMLMapping(doc.Content[0], values[i], prune); err != nil {
+			return nil, err
+		}
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// decodeYAMLDocuments returns the non-empty documents of a YAML file, in the order splitYAMLDocuments does.
+func decodeYAMLDocuments(data []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs []*yaml.Node
+	for {
+		doc := new(yaml.Node)
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, newParseError("", data, err)
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// mergeYAMLMapping updates the values of a YAML mapping node in place, keeping comments and key order.
+// Keys that are not in values are kept, or removed when prune is set. New keys are appended in sorted order.
+func mergeYAMLMapping(mapping *yaml.Node, values map[string]any, prune bool) error {
+	handled := make(map[string]bool, len(values))
+
+	content := mapping.Content[:0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+
+		key, value, found := findFold(values, keyNode.Value)
+		if !found && prune {
+			continue
+		}
+		content = append(content, keyNode, valueNode)
+		if !found {
+			continue
+		}
+		handled[key] = true
+
+		if nested, ok := value.(map[string]any); ok && valueNode.Kind == yaml.MappingNode {
+			if err := mergeYAMLMapping(valueNode, nested, prune); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// keep the original scalar, with its quoting style, when the value did not change
+		var current any
+		if valueNode.Kind == yaml.ScalarNode && valueNode.Decode(&current) == nil && fmt.Sprint(current) == fmt.Sprint(value) {
+			continue
+		}
+
+		newNode := new(yaml.Node)
+		if err := newNode.Encode(value); err != nil {
+			return err
+		}
+		// keep local tags such as !reset, which change how the value is merged
+		if strings.HasPrefix(valueNode.Tag, "!") && !strings.HasPrefix(valueNode.Tag, "!!") {
+			newNode.Tag = valueNode.Tag
+		}
+		newNode.HeadComment = valueNode.HeadComment
+		newNode.LineComment = valueNode.LineComment
+		newNode.FootComment = valueNode.FootComment
+		content[len(content)-1] = newNode
+	}
+	mapping.Content = content
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !handled[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		valueNode := new(yaml.Node)
+		if err := valueNode.Encode(values[key]); err != nil {
+			return err
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package cong
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type saveTestServer struct {
+	Name string `mapstructure:"name"`
+	Port int    `mapstructure:"port"`
+}
+
+type saveTestConfig struct {
+	ServerName string         `mapstructure:"serverName"`
+	Port       int            `mapstructure:"port"`
+	Server     saveTestServer `mapstructure:"server"`
+}
+
+func Test_Loader_Save_yaml(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, `# main settings
+serverName: "HelloWorld" # quoted
+port: 80
+# unknown to the struct
+legacy: true
+server:
+  port: 81 # server port
+`)
+
+	loader := NewLoader[saveTestConfig]()
+
+	config, err := loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+
+	config.Port = 8080
+	config.Server.Name = "api"
+
+	as.Nil(loader.Save(config, path, YamlExt))
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal(`# main settings
+serverName: "HelloWorld" # quoted
+port: 8080
+# unknown to the struct
+legacy: true
+server:
+  port: 81 # server port
+  name: api
+`, string(data))
+}
+
+func Test_Loader_Save_json(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.json")
+	writeTestFile(t, path, `{"ServerName": "HelloWorld", "legacy": true}`)
+
+	loader := NewLoader[saveTestConfig]()
+
+	config, err := loader.Load("hello", JsonExt, filepath.Dir(path))
+	as.Nil(err)
+
+	config.Port = 8080
+	as.Nil(loader.Save(config, path, JsonExt))
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+
+	var saved map[string]any
+	as.Nil(json.Unmarshal(data, &saved))
+	as.Equal(map[string]any{
+		"ServerName": "HelloWorld",
+		"legacy":     true,
+		"port":       float64(8080),
+		"server":     map[string]any{"name": "", "port": float64(0)},
+	}, saved)
+}
+
+func Test_Loader_Save_roundTrip(t *testing.T) {
+	for _, ext := range []ConfigExtension{YamlExt, JsonExt, TomlExt, DotenvExt} {
+		t.Run(ext.String(), func(t *testing.T) {
+			as := assert.New(t)
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "hello."+ext.String())
+			expected := &saveTestConfig{ServerName: "HelloWorld", Port: 80, Server: saveTestServer{Name: "api", Port: 81}}
+
+			loader := NewLoader[saveTestConfig]()
+
+			as.Nil(loader.Save(expected, path, ext))
+
+			config, err := loader.Load("hello", ext, dir)
+			as.Nil(err)
+			as.Equal(expected, config)
+		})
+	}
+}
+
+func Test_Loader_Save_envValues(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Name     string         `mapstructure:"name"`
+		Password string         `mapstructure:"password"`
+		Server   saveTestServer `mapstructure:"server"`
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	writeTestFile(t, path, "name: hello\nserver:\n  port: 80\n")
+
+	loader := NewLoader[TestConfig](WithEnvMap(map[string]string{
+		"HELLO_PASSWORD":    "s3cret",
+		"HELLO_SERVER_PORT": "8080",
+		"HELLO_SERVER_NAME": "from env",
+	}))
+
+	config, err := loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal("s3cret", config.Password)
+
+	config.Name = "world"
+	config.Server.Name = "api"
+
+	as.Nil(loader.Save(config, path, YamlExt))
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal("name: world\nserver:\n  port: 80\n  name: api\n", string(data))
+}
+
+func Test_Loader_Save_multiDocumentYAML(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Name string `mapstructure:"name"`
+		Port int    `mapstructure:"port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	content := "name: base\nport: 80\n---\nprofile: prod\nport: 443\n"
+	writeTestFile(t, path, content)
+
+	loader := NewLoader[TestConfig](WithProfile("prod"))
+
+	config, err := loader.Load("hello", YamlExt, filepath.Dir(path))
+	as.Nil(err)
+	as.Equal(443, config.Port)
+
+	config.Name = "changed"
+
+	as.EqualError(loader.Save(config, path, YamlExt), "saving multi-document YAML files is not supported")
+
+	data, err := os.ReadFile(path)
+	as.Nil(err)
+	as.Equal(content, string(data))
+}
@@ -0,0 +1,258 @@
+package cong
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// JSONSchema is a JSON Schema (draft 2020-12) document or sub-schema describing the keys the loader accepts.
+// Bool is set for the boolean schemas true and false.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	// ReplacedBy is the dotted key of the field an alias key was renamed to.
+	ReplacedBy string `json:"x-replaced-by,omitempty"`
+	Bool       *bool  `json:"-"`
+}
+
+func (schema *JSONSchema) MarshalJSON() ([]byte, error) {
+	if schema.Bool != nil {
+		return json.Marshal(*schema.Bool)
+	}
+
+	type plain JSONSchema
+	return json.Marshal((*plain)(schema))
+}
+
+func (schema *JSONSchema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		schema.Bool = &b
+		return nil
+	}
+
+	type plain JSONSchema
+	return json.Unmarshal(data, (*plain)(schema))
+}
+
+// GenerateJSONSchema describes every key the loader accepts for T, using the same key derivation as the env bindings.
+// Descriptions, defaults, required fields and enums come from the desc, default, required and enum tags:
+//
+//	Level string `mapstructure:"level" default:"info" enum:"debug,info,warn,error" desc:"Log level"`
+//
+// Old keys of the alias tag are marked deprecated, with the key that replaced them. Of the loader options,
+// it uses WithMigrations and WithVersionKey to describe the version key.
+func GenerateJSONSchema[T any](opts ...Option) (*JSONSchema, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	schema, err := typeSchema(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Schema = jsonSchemaDraft
+
+	err = walkFields(reflect.New(typ).Elem(), "", func(key string, field reflect.StructField, _ reflect.Value) error {
+		for _, alias := range aliasKeys(field) {
+			property, err := fieldSchema(field)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			property.Default = nil
+			property.Deprecated = true
+			property.ReplacedBy = key
+
+			addSchemaProperty(schema, strings.Split(alias, "."), property)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	if o.currentVersion != 0 && schemaProperty(schema, o.versionKey) == nil {
+		minimum, maximum := 1.0, float64(o.currentVersion)
+		schema.Properties[o.versionKey] = &JSONSchema{
+			Description: "Config version",
+			Type:        "integer",
+			Minimum:     &minimum,
+			Maximum:     &maximum,
+		}
+	}
+
+	return schema, nil
+}
+
+// addSchemaProperty adds a property at the dotted path, creating the objects of the path that do not exist.
+// Paths below a map or an interface are already allowed and are left alone.
+func addSchemaProperty(schema *JSONSchema, path []string, property *JSONSchema) {
+	if schema.Properties == nil {
+		return
+	}
+
+	existing := schemaProperty(schema, path[0])
+	if len(path) == 1 {
+		if existing == nil {
+			schema.Properties[path[0]] = property
+		}
+		return
+	}
+
+	if existing == nil {
+		existing = &JSONSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*JSONSchema),
+			AdditionalProperties: boolSchema(false),
+		}
+		schema.Properties[path[0]] = existing
+	}
+
+	addSchemaProperty(existing, path[1:], property)
+}
+
+func typeSchema(typ reflect.Type) (*JSONSchema, error) {
+	if inner, ok := optionalInner(typ); ok {
+		return typeSchema(inner)
+	}
+
+	if typ == durationType {
+		return &JSONSchema{Type: "string", Pattern: durationPattern}, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return typeSchema(typ.Elem())
+	case reflect.Struct:
+		schema := &JSONSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*JSONSchema),
+			AdditionalProperties: boolSchema(false),
+		}
+		if err := addStructProperties(schema, typ); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys of type %s are not supported", typ.Key())
+		}
+		valueSchema, err := typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: valueSchema}, nil
+	case reflect.Slice, reflect.Array:
+		itemSchema, err := typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: itemSchema}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &minimum}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Interface:
+		return boolSchema(true), nil
+	default:
+		return nil, fmt.Errorf("type %s is not supported", typ)
+	}
+}
+
+func addStructProperties(schema *JSONSchema, typ reflect.Type) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		if isSquashed(field) {
+			if err := addStructProperties(schema, field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+
+		property, err := fieldSchema(field)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		schema.Properties[name] = property
+		if isRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return nil
+}
+
+func fieldSchema(field reflect.StructField) (*JSONSchema, error) {
+	schema, err := typeSchema(field.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.Bool != nil {
+		schema = &JSONSchema{}
+	}
+
+	schema.Description = field.Tag.Get(descTag)
+	schema.Deprecated = field.Tag.Get(deprecatedTag) != ""
+
+	if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+		schema.Default, err = parseTagValue(field.Type, defaultValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default %q: %w", defaultValue, err)
+		}
+	}
+
+	// enums of list fields constrain the list items
+	enumSchema, enumType := schema, field.Type
+	if inner, ok := optionalInner(enumType); ok {
+		enumType = inner
+	}
+	if schema.Items != nil {
+		enumSchema, enumType = schema.Items, enumType.Elem()
+	}
+	for _, value := range enumValues(field) {
+		enumValue, err := parseTagValue(enumType, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enum value %q: %w", value, err)
+		}
+		enumSchema.Enum = append(enumSchema.Enum, enumValue)
+	}
+
+	return schema, nil
+}
+
+func boolSchema(value bool) *JSONSchema {
+	return &JSONSchema{Bool: &value}
+}
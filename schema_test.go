@@ -0,0 +1,135 @@
+package cong
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateJSONSchema(t *testing.T) {
+	as := assert.New(t)
+
+	type Server struct {
+		Host    string        `mapstructure:"host" required:"true" desc:"Listen host"`
+		Port    uint16        `mapstructure:"port" default:"8080"`
+		Timeout time.Duration `mapstructure:"timeout" default:"10s"`
+	}
+	type TestConfig struct {
+		Level   string            `mapstructure:"level" default:"info" enum:"debug,info,warn"`
+		Server  Server            `mapstructure:"server"`
+		Tags    []string          `mapstructure:"tags"`
+		Headers map[string]string `mapstructure:"headers"`
+		Ignored string            `mapstructure:"-"`
+	}
+
+	schema, err := GenerateJSONSchema[TestConfig]()
+	as.Nil(err)
+
+	data, err := json.Marshal(schema)
+	as.Nil(err)
+
+	as.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"level": {"type": "string", "default": "info", "enum": ["debug", "info", "warn"]},
+			"server": {
+				"type": "object",
+				"additionalProperties": false,
+				"required": ["host"],
+				"properties": {
+					"host": {"type": "string", "description": "Listen host"},
+					"port": {"type": "integer", "minimum": 0, "default": 8080},
+					"timeout": {"type": "string", "pattern": "^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$", "default": "10s"}
+				}
+			},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"headers": {"type": "object", "additionalProperties": {"type": "string"}}
+		}
+	}`, string(data))
+}
+
+func Test_GenerateJSONSchema_aliasesAndVersion(t *testing.T) {
+	as := assert.New(t)
+
+	type Server struct {
+		Port int    `mapstructure:"port" alias:"server.listen,port" required:"true"`
+		Mode string `mapstructure:"mode" deprecated:"use tls instead"`
+	}
+	type TestConfig struct {
+		Server Server `mapstructure:"server"`
+	}
+
+	schema, err := GenerateJSONSchema[TestConfig](WithMigrations(3))
+	as.Nil(err)
+
+	data, err := json.Marshal(schema)
+	as.Nil(err)
+
+	as.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"server": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"port": {"type": "integer"},
+					"mode": {"type": "string", "deprecated": true},
+					"listen": {"type": "integer", "deprecated": true, "x-replaced-by": "server.port"}
+				},
+				"required": ["port"]
+			},
+			"port": {"type": "integer", "deprecated": true, "x-replaced-by": "server.port"},
+			"version": {"description": "Config version", "type": "integer", "minimum": 1, "maximum": 3}
+		}
+	}`, string(data))
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "config.yaml"), "version: 3\nserver:\n  listen: 80\n")
+
+	as.Nil(NewLoader[TestConfig](WithMigrations(3)).Check(dir))
+	as.Nil(CheckWithSchema(schema, dir))
+}
+
+func Test_GenerateJSONSchema_invalidDefault(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Port int `default:"eighty"`
+	}
+
+	_, err := GenerateJSONSchema[TestConfig]()
+
+	as.ErrorContains(err, "Port")
+}
+
+func Test_Loader_LoadFromEnv_withDefaultTags(t *testing.T) {
+	as := assert.New(t)
+
+	t.Setenv("HELLO_PORT", "8080")
+
+	type TestConfig struct {
+		ServerName string        `default:"HelloWorld"`
+		Port       int           `default:"80"`
+		Timeout    time.Duration `default:"20s"`
+		Tags       []string      `default:"a,b"`
+	}
+
+	loader := NewLoader[TestConfig]()
+
+	config, err := loader.LoadFromEnv("hello")
+
+	as.Nil(err)
+	as.Equal(&TestConfig{
+		ServerName: "HelloWorld",
+		Port:       8080,
+		Timeout:    20 * time.Second,
+		Tags:       []string{"a", "b"},
+	}, config)
+}
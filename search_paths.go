@@ -0,0 +1,210 @@
+package cong
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// StandardSearchPaths returns the standard config locations for the project, ordered from the highest
+// to the lowest precedence:
+//
+//  1. entries of the <PROJECT>_CONFIG_PATH environment variable (os.PathListSeparator separated);
+//  2. the default local paths ".", "./config" and "./static";
+//  3. the directory of the running executable;
+//  4. $XDG_CONFIG_HOME/<project> (or $HOME/.config/<project> when XDG_CONFIG_HOME is unset);
+//  5. $HOME/.<project>;
+//  6. /etc/<project> (not on Windows).
+func StandardSearchPaths(projectName string) []string {
+	return standardSearchPaths(projectName, os.LookupEnv)
+}
+
+func standardSearchPaths(projectName string, lookupEnv envLookup) []string {
+	paths := make([]string, 0, len(defaultConfigPaths)+6)
+
+	if envPaths, _ := lookupEnv(strings.ToUpper(projectName) + "_CONFIG_PATH"); envPaths != "" {
+		paths = append(paths, filepath.SplitList(envPaths)...)
+	}
+
+	paths = append(paths, defaultConfigPaths...)
+
+	if executable, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Dir(executable))
+	}
+
+	home := userHomeDir(lookupEnv)
+
+	if xdgConfigHome, _ := lookupEnv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		paths = append(paths, filepath.Join(xdgConfigHome, projectName))
+	} else if home != "" {
+		paths = append(paths, filepath.Join(home, ".config", projectName))
+	}
+
+	if home != "" {
+		paths = append(paths, filepath.Join(home, "."+projectName))
+	}
+
+	if runtime.GOOS != "windows" {
+		paths = append(paths, filepath.Join("/etc", projectName))
+	}
+
+	return paths
+}
+
+// userHomeDir reads the home directory from the environment the way os.UserHomeDir does.
+func userHomeDir(lookupEnv envLookup) string {
+	name := "HOME"
+	switch runtime.GOOS {
+	case "windows":
+		name = "USERPROFILE"
+	case "plan9":
+		name = "home"
+	}
+
+	home, _ := lookupEnv(name)
+	return home
+}
+
+func (loader *Loader[T]) searchPaths(projectName string, configPaths []string) []string {
+	switch {
+	case len(configPaths) != 0:
+		return configPaths
+	case len(loader.options.searchPaths) != 0:
+		return loader.options.searchPaths
+	case loader.options.standardSearchPaths:
+		return standardSearchPaths(projectName, loader.environment())
+	default:
+		return defaultConfigPaths
+	}
+}
+
+// findConfigFilesInPaths returns the config files to merge, ordered from the lowest to the highest precedence.
+// Unless merging of all found files is enabled, only the first match is returned.
+func (loader *Loader[T]) findConfigFilesInPaths(projectName string, ext ConfigExtension, paths []string) ([]string, error) {
+	configsPaths := make([]string, 0)
+
+	for _, path := range paths {
+		file := filepath.Join(path, projectName+"."+ext.String())
+
+		info, err := os.Stat(file)
+		if os.IsNotExist(err) {
+			loader.options.logger.Debug("config search path", "path", path, "file", file, "found", false)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			loader.options.logger.Debug("config search path", "path", path, "file", file, "found", false)
+			continue
+		}
+
+		loader.options.logger.Debug("config search path", "path", path, "file", file, "found", true)
+		configsPaths = append([]string{file}, configsPaths...)
+		if !loader.options.mergeAllFound {
+			break
+		}
+	}
+
+	if len(configsPaths) == 0 {
+		return nil, &NotFoundError{Name: projectName, Ext: ext, Paths: paths}
+	}
+	loader.options.logger.Debug("config files found", "files", configsPaths)
+
+	return configsPaths, nil
+}
+
+// findConfigFiles resolves the files Load should merge: the explicit config file when one is set,
+// otherwise the matches in the search paths.
+func (loader *Loader[T]) findConfigFiles(
+	state *loadState,
+	projectName string,
+	ext ConfigExtension,
+	configPaths []string,
+) ([]string, ConfigExtension, error) {
+	configFile, err := loader.explicitConfigFile(state, projectName)
+	if err != nil {
+		return nil, ext, err
+	}
+	if configFile != "" {
+		return loader.findExplicitConfigFile(configFile, ext)
+	}
+
+	paths := loader.searchPaths(projectName, configPaths)
+
+	configsPaths, err := loader.findConfigFilesInPaths(projectName, ext, paths)
+	var notFoundErr *NotFoundError
+	if err != nil && (!loader.options.optionalFile || !errors.As(err, &notFoundErr)) {
+		return nil, ext, err
+	}
+	if err != nil {
+		loader.options.logger.Debug("optional config file not found", "paths", paths)
+	}
+
+	return configsPaths, ext, nil
+}
+
+// explicitConfigFile returns the config file set via the config flag, WithConfigFile or, with WithConfigEnv,
+// the <PROJECT>_CONFIG environment variable, in that order of precedence.
+func (loader *Loader[T]) explicitConfigFile(state *loadState, projectName string) (string, error) {
+	if loader.options.configFlagSet != nil {
+		if configFlag := loader.options.configFlagSet.Lookup(loader.options.configFlagName); configFlag != nil {
+			if value := configFlag.Value.String(); value != "" {
+				loader.options.logger.Debug("explicit config file", "file", value, "source", "flag "+configFlag.Name)
+				return value, nil
+			}
+		}
+	}
+
+	if loader.options.configFile != "" {
+		loader.options.logger.Debug("explicit config file", "file", loader.options.configFile, "source", "option")
+		return loader.options.configFile, nil
+	}
+
+	if !loader.options.configEnv {
+		return "", nil
+	}
+
+	envVar := strings.ToUpper(projectName) + "_CONFIG"
+	for _, bound := range state.boundFields {
+		if slices.Contains(bound.envVars, envVar) {
+			return "", fmt.Errorf("field %s is bound to %s, which WithConfigEnv reads as the config file path", bound.key, envVar)
+		}
+	}
+
+	value, _ := loader.environment()(envVar)
+	if value != "" {
+		loader.options.logger.Debug("explicit config file", "file", value, "source", "env "+envVar)
+	}
+
+	return value, nil
+}
+
+func (loader *Loader[T]) findExplicitConfigFile(path string, ext ConfigExtension) ([]string, ConfigExtension, error) {
+	fileExt, err := configExtensionFromPath(path, ext)
+	if err != nil {
+		return nil, ext, err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, ext, &NotFoundError{
+			Name:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Ext:   fileExt,
+			Paths: []string{filepath.Dir(path)},
+			Err:   err,
+		}
+	}
+	if err != nil {
+		return nil, ext, err
+	}
+	if info.IsDir() {
+		return nil, ext, fmt.Errorf("config file %s is a directory", path)
+	}
+
+	return []string{path}, fileExt, nil
+}
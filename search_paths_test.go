@@ -0,0 +1,187 @@
+package cong
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_StandardSearchPaths(t *testing.T) {
+	as := assert.New(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv("HELLO_CONFIG_PATH", "/first"+string(os.PathListSeparator)+"/second")
+
+	paths := StandardSearchPaths("hello")
+
+	as.Equal([]string{"/first", "/second", ".", "./config", "./static"}, paths[:5])
+	as.Equal([]string{
+		filepath.Join(home, "xdg", "hello"),
+		filepath.Join(home, ".hello"),
+		"/etc/hello",
+	}, paths[len(paths)-3:])
+}
+
+func Test_Loader_Load_withStandardSearchPaths(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	writeTestFile(t, filepath.Join(home, ".searchtest", "searchtest.yaml"), "serverName: home\nport: 80\n")
+	writeTestFile(t, filepath.Join(home, "xdg", "searchtest", "searchtest.yaml"), "serverName: xdg\n")
+
+	loader := NewLoader[TestConfig](WithStandardSearchPaths())
+
+	config, err := loader.Load("searchtest", YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "xdg"}, config)
+}
+
+func Test_Loader_Load_withMergeAllFound(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv("SEARCHTEST_CONFIG_PATH", filepath.Join(home, "env"))
+	writeTestFile(t, filepath.Join(home, ".searchtest", "searchtest.yaml"), "serverName: home\nport: 80\ntimeout: 5\n")
+	writeTestFile(t, filepath.Join(home, "xdg", "searchtest", "searchtest.yaml"), "serverName: xdg\nport: 8080\n")
+	writeTestFile(t, filepath.Join(home, "env", "searchtest.yaml"), "serverName: env\n")
+
+	loader := NewLoader[TestConfig](WithStandardSearchPaths(), WithMergeAllFound())
+
+	config, err := loader.Load("searchtest", YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "env", Port: 8080, Timeout: 5}, config)
+}
+
+func Test_Loader_Load_withSearchPaths(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	loader := NewLoader[TestConfig](WithSearchPaths("./testdata/missing", "./testdata/loadYaml"))
+
+	config, err := loader.Load("hello", YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "HelloWorld", Port: 80, Timeout: 20}, config)
+}
+
+func Test_Loader_Load_withConfigFileEnv(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	file := filepath.Join(t.TempDir(), "custom.json")
+	writeTestFile(t, file, `{"serverName": "custom", "port": 9090}`)
+	t.Setenv("HELLO_CONFIG", file)
+
+	config, err := NewLoader[TestConfig](WithConfigEnv()).Load("hello", YamlExt, "./testdata/loadYaml")
+
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "custom", Port: 9090}, config)
+
+	config, err = NewLoader[TestConfig]().Load("hello", YamlExt, "./testdata/loadYaml")
+
+	as.Nil(err)
+	as.Equal("HelloWorld", config.ServerName)
+}
+
+func Test_Loader_Load_withConfigFileEnv_boundField(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Config string `mapstructure:"config"`
+	}
+
+	t.Setenv("HELLO_CONFIG", "value")
+
+	_, err := NewLoader[TestConfig](WithConfigEnv()).Load("hello", YamlExt, "./testdata/loadYaml")
+	as.ErrorContains(err, "field config is bound to HELLO_CONFIG")
+
+	config, err := NewLoader[TestConfig]().Load("hello", YamlExt, "./testdata/loadYaml")
+	as.Nil(err)
+	as.Equal("value", config.Config)
+}
+
+func Test_Loader_Load_withConfigFlag(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+		Port       int
+		Timeout    int
+	}
+
+	file := filepath.Join(t.TempDir(), "custom.yaml")
+	writeTestFile(t, file, "serverName: flag\n")
+	t.Setenv("HELLO_CONFIG", "./testdata/loadYaml/hello.yaml")
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.String("config", "", "config file")
+	as.Nil(flagSet.Parse([]string{"--config", file}))
+
+	loader := NewLoader[TestConfig](WithConfigFlag(flagSet, "config"), WithConfigEnv())
+
+	config, err := loader.Load("hello", YamlExt)
+
+	as.Nil(err)
+	as.Equal(&TestConfig{ServerName: "flag"}, config)
+}
+
+func Test_Loader_Load_withConfigFileMissing(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		ServerName string
+	}
+
+	loader := NewLoader[TestConfig](WithConfigFile("./testdata/missing/custom.yaml"), WithOptionalFile())
+
+	_, err := loader.Load("hello", YamlExt)
+
+	var notFoundErr *NotFoundError
+	as.True(errors.As(err, &notFoundErr))
+	as.Equal("custom", notFoundErr.Name)
+}
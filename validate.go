@@ -0,0 +1,410 @@
+package cong
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// FieldError describes a config key that failed validation.
+// EnvVar is the environment variable that can be used to fix it and is empty when env binding is disabled.
+// For rules spanning several keys (see Rule), Keys lists all of them.
+type FieldError struct {
+	Key     string
+	Keys    []string
+	EnvVar  string
+	Rule    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	var env string
+	if e.EnvVar != "" {
+		env = " (env " + e.EnvVar + ")"
+	}
+
+	return fmt.Sprintf("key %q%s %s", e.Key, env, e.Message)
+}
+
+// ValidationError lists every config key that failed validation.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Error())
+	}
+
+	return "config validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validator is implemented by configs, or nested config structs, that check themselves after the validation tags,
+// e.g. for rules the tags cannot express. Its error is returned along with the tag validation errors.
+type Validator interface {
+	Validate() error
+}
+
+// validationRule checks a set field against the parameter of its tag and returns a message when the value breaks it.
+type validationRule struct {
+	tag   string
+	check func(value reflect.Value, param string) string
+}
+
+// validationRules are run in order on every field that is set. Element rules apply to every item of a list.
+var validationRules = []validationRule{
+	{tag: minTag, check: checkMin},
+	{tag: maxTag, check: checkMax},
+	{tag: lenTag, check: checkLen},
+	{tag: regexTag, check: eachElement(checkRegex)},
+	{tag: urlTag, check: flagRule(eachElement(checkURL))},
+	{tag: hostnameTag, check: flagRule(eachElement(checkHostname))},
+	{tag: portTag, check: flagRule(eachElement(checkPort))},
+	{tag: fileExistsTag, check: flagRule(eachElement(checkFileExists))},
+	{tag: dirExistsTag, check: flagRule(eachElement(checkDirExists))},
+}
+
+// validate checks the validation tags and the cross-field rules of the decoded config, then calls the Validator hooks.
+// Rules other than required and nonempty only apply to keys that are set.
+func (loader *Loader[T]) validate(state *loadState, config *T) error {
+	var fieldErrs []*FieldError
+
+	_ = walkFields(reflect.ValueOf(config).Elem(), "", func(key string, field reflect.StructField, value reflect.Value) error {
+		envVar := state.boundFields[strings.ToLower(key)].envVar
+		isSet := state.viper.IsSet(key)
+
+		addError := func(rule string, message string) {
+			fieldErrs = append(fieldErrs, &FieldError{Key: key, EnvVar: envVar, Rule: rule, Message: message})
+		}
+
+		if isRequired(field) && !isSet {
+			addError(requiredTag, "is required")
+			return nil
+		}
+
+		if hasFlagTag(field, nonemptyTag) && isEmpty(value) {
+			addError(nonemptyTag, "must not be empty")
+			return nil
+		}
+
+		value, ok := indirect(value)
+		if !isSet || !ok {
+			return nil
+		}
+
+		if enum := enumValues(field); enum != nil && !inEnum(value, enum) {
+			rule := enumTag
+			if _, ok := field.Tag.Lookup(enumTag); !ok {
+				rule = oneofTag
+			}
+			addError(rule, fmt.Sprintf("must be one of [%s], got %v", strings.Join(enum, ", "), value.Interface()))
+		}
+
+		for _, rule := range validationRules {
+			if param, ok := field.Tag.Lookup(rule.tag); ok {
+				if message := rule.check(value, param); message != "" {
+					addError(rule.tag, message)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	fieldErrs = append(fieldErrs, loader.checkRules(state, config)...)
+
+	var errs []error
+	if len(fieldErrs) > 0 {
+		errs = append(errs, &ValidationError{Errors: fieldErrs})
+	}
+	errs = append(errs, callValidators(reflect.ValueOf(config).Elem())...)
+
+	return errors.Join(errs...)
+}
+
+// callValidators calls the Validator hooks of a struct and of its nested structs, outermost first.
+func callValidators(val reflect.Value) []error {
+	var errs []error
+
+	if validator, ok := val.Addr().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if _, ok := fieldName(typ.Field(i)); !ok {
+			continue
+		}
+
+		if field, ok := indirect(val.Field(i)); ok && field.Kind() == reflect.Struct && field.CanAddr() {
+			errs = append(errs, callValidators(field)...)
+		}
+	}
+
+	return errs
+}
+
+// indirect dereferences pointers and unwraps Optional values; the second result is false for nil pointers
+// and unset Optional values.
+func indirect(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+
+	if _, ok := optionalInner(value.Type()); ok {
+		if !value.Field(1).Bool() {
+			return value, false
+		}
+		return indirect(value.Field(0))
+	}
+
+	return value, true
+}
+
+func isEmpty(value reflect.Value) bool {
+	value, ok := indirect(value)
+	if !ok {
+		return true
+	}
+
+	if length, ok := lengthOf(value); ok {
+		return length == 0
+	}
+
+	return value.IsZero()
+}
+
+func lengthOf(value reflect.Value) (int, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return len([]rune(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return value.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+func numberOf(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// compareBound compares the value, or its length for strings, lists and maps, with the parameter of a min or max tag.
+// It returns the sign of value-bound, what was compared for messages, and a message when the tag cannot apply.
+func compareBound(value reflect.Value, tag string, param string) (int, string, string) {
+	if value.Type() == durationType {
+		bound, err := time.ParseDuration(param)
+		if err != nil {
+			return 0, "", fmt.Sprintf("has an invalid %s tag %q", tag, param)
+		}
+		return compareNumbers(float64(value.Int()), float64(bound)), value.Interface().(fmt.Stringer).String(), ""
+	}
+
+	if length, ok := lengthOf(value); ok {
+		bound, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, "", fmt.Sprintf("has an invalid %s tag %q", tag, param)
+		}
+		return compareNumbers(float64(length), float64(bound)), "length " + strconv.Itoa(length), ""
+	}
+
+	if number, ok := numberOf(value); ok {
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, "", fmt.Sprintf("has an invalid %s tag %q", tag, param)
+		}
+		return compareNumbers(number, bound), fmt.Sprint(value.Interface()), ""
+	}
+
+	return 0, "", fmt.Sprintf("cannot be checked with %s for type %s", tag, value.Type())
+}
+
+func compareNumbers(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func checkMin(value reflect.Value, param string) string {
+	sign, got, message := compareBound(value, minTag, param)
+	if message == "" && sign < 0 {
+		message = fmt.Sprintf("must be at least %s, got %s", param, got)
+	}
+
+	return message
+}
+
+func checkMax(value reflect.Value, param string) string {
+	sign, got, message := compareBound(value, maxTag, param)
+	if message == "" && sign > 0 {
+		message = fmt.Sprintf("must be at most %s, got %s", param, got)
+	}
+
+	return message
+}
+
+func checkLen(value reflect.Value, param string) string {
+	length, ok := lengthOf(value)
+	if !ok {
+		return fmt.Sprintf("cannot be checked with len for type %s", value.Type())
+	}
+
+	expected, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Sprintf("has an invalid len tag %q", param)
+	}
+
+	if length != expected {
+		return fmt.Sprintf("must have length %d, got %d", expected, length)
+	}
+
+	return ""
+}
+
+// flagRule runs check only when the tag is set to true, e.g. url:"true".
+func flagRule(check func(value reflect.Value, param string) string) func(value reflect.Value, param string) string {
+	return func(value reflect.Value, param string) string {
+		if enabled, _ := strconv.ParseBool(param); !enabled {
+			return ""
+		}
+
+		return check(value, param)
+	}
+}
+
+// eachElement applies an element rule to every item of a list, or to the value itself.
+func eachElement(check func(value reflect.Value, param string) string) func(value reflect.Value, param string) string {
+	return func(value reflect.Value, param string) string {
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return check(value, param)
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			item, ok := indirect(value.Index(i))
+			if !ok {
+				continue
+			}
+			if message := check(item, param); message != "" {
+				return fmt.Sprintf("item %d %s", i, message)
+			}
+		}
+
+		return ""
+	}
+}
+
+func checkRegex(value reflect.Value, pattern string) string {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Sprintf("has an invalid regex tag %q: %v", pattern, err)
+	}
+
+	if !re.MatchString(fmt.Sprint(value.Interface())) {
+		return fmt.Sprintf("must match %s, got %v", pattern, value.Interface())
+	}
+
+	return ""
+}
+
+func checkURL(value reflect.Value, _ string) string {
+	parsed, err := url.Parse(fmt.Sprint(value.Interface()))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Sprintf("must be an absolute URL, got %q", value.Interface())
+	}
+
+	return ""
+}
+
+func checkHostname(value reflect.Value, _ string) string {
+	hostname := fmt.Sprint(value.Interface())
+	if len(hostname) > 253 || !hostnameRegexp.MatchString(hostname) {
+		return fmt.Sprintf("must be a valid hostname, got %q", hostname)
+	}
+
+	return ""
+}
+
+func checkPort(value reflect.Value, _ string) string {
+	port, ok := numberOf(value)
+	if !ok {
+		parsed, err := strconv.Atoi(fmt.Sprint(value.Interface()))
+		port, ok = float64(parsed), err == nil
+	}
+
+	if !ok || port != float64(int(port)) || port < 1 || port > 65535 {
+		return fmt.Sprintf("must be a port between 1 and 65535, got %v", value.Interface())
+	}
+
+	return ""
+}
+
+func checkFileExists(value reflect.Value, _ string) string {
+	path := fmt.Sprint(value.Interface())
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return fmt.Sprintf("must be an existing file, got %q", path)
+	}
+
+	return ""
+}
+
+func checkDirExists(value reflect.Value, _ string) string {
+	path := fmt.Sprint(value.Interface())
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return fmt.Sprintf("must be an existing directory, got %q", path)
+	}
+
+	return ""
+}
+
+func inEnum(value reflect.Value, enum []string) bool {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			if !inEnum(value.Index(i), enum) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return slices.Contains(enum, fmt.Sprint(value.Interface()))
+}
@@ -0,0 +1,167 @@
+package cong
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateTestServer struct {
+	Host    string        `mapstructure:"host" hostname:"true"`
+	Port    int           `mapstructure:"port" port:"true"`
+	Timeout time.Duration `mapstructure:"timeout" min:"1s" max:"1m"`
+}
+
+type validateTestConfig struct {
+	Name    string             `mapstructure:"name" nonempty:"true" min:"3" max:"10"`
+	Code    string             `mapstructure:"code" len:"2" regex:"^[A-Z]+$"`
+	Level   string             `mapstructure:"level" oneof:"debug,info"`
+	Workers int                `mapstructure:"workers" min:"1" max:"16"`
+	Ratio   float64            `mapstructure:"ratio" max:"1"`
+	Tags    []string           `mapstructure:"tags" min:"1" regex:"^[a-z]+$"`
+	Webhook string             `mapstructure:"webhook" url:"true"`
+	CAFile  string             `mapstructure:"caFile" file_exists:"true"`
+	DataDir string             `mapstructure:"dataDir" dir_exists:"true"`
+	Server  validateTestServer `mapstructure:"server"`
+}
+
+func Test_Loader_validate_valid(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeTestFile(t, caFile, "cert")
+
+	config, err := NewLoader[validateTestConfig]().LoadFromMap("", map[string]any{
+		"name":    "hello",
+		"code":    "EU",
+		"level":   "info",
+		"workers": 4,
+		"ratio":   0.5,
+		"tags":    []any{"web", "api"},
+		"webhook": "https://example.com/hook",
+		"caFile":  caFile,
+		"dataDir": dir,
+		"server":  map[string]any{"host": "api.example.com", "port": 8080, "timeout": "30s"},
+	})
+
+	as.Nil(err)
+	as.Equal("hello", config.Name)
+}
+
+func Test_Loader_validate_unsetKeysAreSkipped(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := NewLoader[validateTestConfig]().LoadFromMap("", map[string]any{"name": "hello"})
+
+	as.Nil(err)
+}
+
+func Test_Loader_validate_allFailures(t *testing.T) {
+	as := assert.New(t)
+
+	caFile := filepath.Join(t.TempDir(), "missing.pem")
+	dataDir := filepath.Join(t.TempDir(), "missing")
+
+	_, err := NewLoader[validateTestConfig](WithEnvMap(nil)).LoadFromMap("hello", map[string]any{
+		"code":    "e",
+		"level":   "trace",
+		"workers": 0,
+		"ratio":   1.5,
+		"tags":    []any{"web", "API"},
+		"webhook": "example.com",
+		"caFile":  caFile,
+		"dataDir": dataDir,
+		"server":  map[string]any{"host": "-bad-", "port": 70000, "timeout": "2m"},
+	})
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+
+	failures := make(map[string]string)
+	for _, fieldErr := range validationErr.Errors {
+		failures[fieldErr.Key+" "+fieldErr.Rule] = fieldErr.Message
+	}
+
+	as.Equal(map[string]string{
+		"name nonempty":        "must not be empty",
+		"code len":             "must have length 2, got 1",
+		"code regex":           "must match ^[A-Z]+$, got e",
+		"level oneof":          "must be one of [debug, info], got trace",
+		"workers min":          "must be at least 1, got 0",
+		"ratio max":            "must be at most 1, got 1.5",
+		"tags regex":           "item 1 must match ^[a-z]+$, got API",
+		"webhook url":          `must be an absolute URL, got "example.com"`,
+		"caFile file_exists":   `must be an existing file, got "` + caFile + `"`,
+		"dataDir dir_exists":   `must be an existing directory, got "` + dataDir + `"`,
+		"server.host hostname": `must be a valid hostname, got "-bad-"`,
+		"server.port port":     "must be a port between 1 and 65535, got 70000",
+		"server.timeout max":   "must be at most 1m, got 2m0s",
+	}, failures)
+	as.Equal("HELLO_SERVER_PORT", validationErr.Errors[len(validationErr.Errors)-2].EnvVar)
+}
+
+type validateTestHooked struct {
+	Min    int                 `mapstructure:"min"`
+	Max    int                 `mapstructure:"max"`
+	Nested *validateTestNested `mapstructure:"nested"`
+	Name   string              `mapstructure:"name" min:"3"`
+}
+
+type validateTestNested struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+func (c *validateTestHooked) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("min must not be greater than max")
+	}
+
+	return nil
+}
+
+func (n validateTestNested) Validate() error {
+	if !n.Enabled {
+		return errors.New("nested must be enabled")
+	}
+
+	return nil
+}
+
+func Test_Loader_validate_hook(t *testing.T) {
+	as := assert.New(t)
+
+	loader := NewLoader[validateTestHooked]()
+
+	_, err := loader.LoadFromMap("", map[string]any{"min": 2, "max": 1, "name": "ab", "nested": map[string]any{"enabled": false}})
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Equal("name", validationErr.Errors[0].Key)
+	as.ErrorContains(err, "min must not be greater than max")
+	as.ErrorContains(err, "nested must be enabled")
+
+	_, err = loader.LoadFromMap("", map[string]any{"min": 1, "max": 2, "nested": map[string]any{"enabled": true}})
+	as.Nil(err)
+}
+
+func Test_Check_validationTags(t *testing.T) {
+	as := assert.New(t)
+
+	type TestConfig struct {
+		Port int `mapstructure:"port" port:"true"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	as.Nil(os.WriteFile(path, []byte("port: 0\n"), 0o644))
+
+	err := Check[TestConfig](path)
+
+	var validationErr *ValidationError
+	as.True(errors.As(err, &validationErr))
+	as.Equal("port", validationErr.Errors[0].Rule)
+}